@@ -0,0 +1,161 @@
+package perform
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Condition that stops the test prematurely. Criteria are evaluated over measured runs of all tasks.
+type AbortCriterion struct {
+	name     string
+	window   int           // number of last runs evaluated
+	ratio    float64       // max failure ratio
+	failures int           // max consecutive failures
+	latency  time.Duration // max p99 latency
+}
+
+// Description of a fired abort criterion
+type AbortEvent struct {
+	// Fired criterion
+	Criterion string `json,yaml:"criterion"`
+	// Time when the criterion fired
+	Time time.Time `json,yaml:"time"`
+	// Number of measured runs completed by that time
+	Runs int `json,yaml:"runs"`
+}
+
+// Aborts the test when the ratio of failed runs among the last "window" runs exceeds "ratio"
+func MaxFailureRatio(ratio float64, window int) AbortCriterion {
+	if window <= 0 || ratio < 0 || ratio >= 1 {
+		panic("failure ratio should be in [0, 1) and window should be positive")
+	}
+	return AbortCriterion{name: fmt.Sprintf("failure ratio > %g over %d runs", ratio, window), window: window, ratio: ratio}
+}
+
+// Aborts the test after "n" failed runs in a row
+func MaxConsecutiveFailures(n int) AbortCriterion {
+	if n <= 0 {
+		panic("number of consecutive failures should be positive")
+	}
+	return AbortCriterion{name: fmt.Sprintf("%d consecutive failures", n), failures: n}
+}
+
+// Aborts the test when p99 latency of the last "window" runs exceeds "limit"
+func MaxP99(limit time.Duration, window int) AbortCriterion {
+	if window <= 0 || limit <= 0 {
+		panic("latency limit and window should be positive")
+	}
+	return AbortCriterion{name: fmt.Sprintf("p99 > %v over %d runs", limit, window), window: window, latency: limit}
+}
+
+// Sets criteria that stop the test prematurely. When a criterion fires, dispatching of new runs stops,
+// in-flight runs are completed and the event is reported in `RunStats.Abort` of the incomplete statistics.
+func WithAbortCriteria(criteria ...AbortCriterion) RunOption {
+	return func(c *runConfig) {
+		c.abortCriteria = append(c.abortCriteria, criteria...)
+	}
+}
+
+// Evaluates abort criteria on results of measured runs
+type abortMonitor struct {
+	criteria    []AbortCriterion
+	abort       func()
+	lock        sync.Mutex
+	runs        int
+	consecutive int         // current number of failures in a row
+	failed      []bool      // ring buffer of last run outcomes
+	times       []float64   // ring buffer of last run latencies
+	event       *AbortEvent // first fired criterion
+	sorted      []float64   // scratch buffer for percentile calculation
+	bufSize     int
+}
+
+func newAbortMonitor(criteria []AbortCriterion, abort func()) *abortMonitor {
+	if len(criteria) == 0 {
+		return nil
+	}
+	bufSize := 0
+	for _, c := range criteria {
+		bufSize = max(bufSize, c.window)
+	}
+	return &abortMonitor{criteria: criteria, abort: abort, bufSize: bufSize,
+		failed: make([]bool, bufSize), times: make([]float64, bufSize), sorted: make([]float64, 0, bufSize)}
+}
+
+// Registers result of a measured run and aborts the test when a criterion fires
+func (m *abortMonitor) check(result RunResult) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.bufSize > 0 {
+		pos := m.runs % m.bufSize
+		m.failed[pos] = result.Err != nil
+		m.times[pos] = float64(result.Duration) / msecFctr
+	}
+	m.runs++
+	if result.Err != nil {
+		m.consecutive++
+	} else {
+		m.consecutive = 0
+	}
+
+	if m.event != nil {
+		return
+	}
+	for _, c := range m.criteria {
+		if m.fired(c) {
+			m.event = &AbortEvent{Criterion: c.name, Time: time.Now(), Runs: m.runs}
+			m.abort()
+			return
+		}
+	}
+}
+
+func (m *abortMonitor) fired(c AbortCriterion) bool {
+	switch {
+	case c.failures > 0:
+		return m.consecutive >= c.failures
+	case m.runs < c.window:
+		return false
+	case c.latency > 0:
+		return m.lastPercentile(c.window, 99) > float64(c.latency)/msecFctr
+	default:
+		return float64(m.lastFailures(c.window))/float64(c.window) > c.ratio
+	}
+}
+
+// Number of failures among the last "n" runs
+func (m *abortMonitor) lastFailures(n int) int {
+	ret := 0
+	for i := 1; i <= n; i++ {
+		if m.failed[(m.runs-i)%m.bufSize] {
+			ret++
+		}
+	}
+	return ret
+}
+
+// Percentile of latencies of the last "n" runs
+func (m *abortMonitor) lastPercentile(n int, level float64) float64 {
+	m.sorted = m.sorted[:0]
+	for i := 1; i <= n; i++ {
+		m.sorted = append(m.sorted, m.times[(m.runs-i)%m.bufSize])
+	}
+	sort.Float64s(m.sorted)
+	return percentile(m.sorted, level)
+}
+
+// Fired criterion if any
+func (m *abortMonitor) firedEvent() *AbortEvent {
+	if m == nil {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.event
+}
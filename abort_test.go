@@ -0,0 +1,71 @@
+package perform
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbortConsecutiveFailures(t *testing.T) {
+	assertT := assert.New(t)
+
+	startTime := time.Now()
+	stats := RunTestContext(context.Background(), []ContextTask{failingTask}, 10*TotalTests, Parallel,
+		WithAbortCriteria(MaxConsecutiveFailures(5)))
+
+	assertT.True(stats[0].Incomplete)
+	assertT.NotNil(stats[0].Abort)
+	assertT.Equal("5 consecutive failures", stats[0].Abort.Criterion)
+	assertT.GreaterOrEqual(stats[0].Abort.Runs, 5)
+	assertT.True(stats[0].Abort.Time.After(startTime))
+	assertT.Less(stats[0].Count, 10*TotalTests)
+	assertT.GreaterOrEqual(stats[0].Count, stats[0].Abort.Runs)
+}
+
+func TestAbortFailureRatio(t *testing.T) {
+	assertT := assert.New(t)
+
+	tasks := []ContextTask{sleepTask, failingTask}
+	stats := RunTestContext(context.Background(), tasks, 10*TotalTests, Parallel,
+		WithAbortCriteria(MaxConsecutiveFailures(TotalTests), MaxFailureRatio(0.3, 20)))
+
+	for _, oneStat := range stats {
+		assertT.True(oneStat.Incomplete)
+		assertT.Equal("failure ratio > 0.3 over 20 runs", oneStat.Abort.Criterion)
+		assertT.GreaterOrEqual(oneStat.Abort.Runs, 20)
+	}
+	assertT.Less(stats[0].Count+stats[1].Count, 10*TotalTests)
+}
+
+func TestAbortLatency(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats, _ := RunTestFor(context.Background(), []ContextTask{sleepTask}, time.Minute, 0, Parallel,
+		WithAbortCriteria(MaxP99(SleepTime/2, 10)))
+
+	assertT.True(stats[0].Incomplete)
+	assertT.Equal("p99 > 5ms over 10 runs", stats[0].Abort.Criterion)
+	assertT.Less(stats[0].Count, TotalTests)
+}
+
+func TestAbortNotFired(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats := RunTestContext(context.Background(), []ContextTask{sleepTask}, TotalTests, Parallel,
+		WithAbortCriteria(MaxFailureRatio(0, 10), MaxConsecutiveFailures(1), MaxP99(time.Second, 10)))
+
+	assertT.False(stats[0].Incomplete)
+	assertT.Nil(stats[0].Abort)
+	assertT.Equal(TotalTests, stats[0].Count)
+}
+
+func TestAbortCriteriaPanic(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Panics(func() { MaxFailureRatio(1, 10) })
+	assertT.Panics(func() { MaxFailureRatio(0.5, 0) })
+	assertT.Panics(func() { MaxConsecutiveFailures(0) })
+	assertT.Panics(func() { MaxP99(0, 10) })
+}
@@ -0,0 +1,186 @@
+package perform
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Method of bootstrap confidence interval calculation
+type IntervalMethod int
+
+const (
+	// Quantiles of the bootstrap distribution
+	PercentileInterval IntervalMethod = iota
+	// Bias-corrected and accelerated quantiles - more accurate for skewed distributions
+	BCaInterval
+)
+
+// Parameters of bootstrap estimation
+type BootstrapConfig struct {
+	// Number of resamples; 10000 if zero
+	Resamples int
+	// Confidence level of intervals; 0.95 if zero
+	Confidence float64
+	// Interval calculation method
+	Method IntervalMethod
+	// Seed of random generator - same seed produces same intervals
+	Seed int64
+}
+
+// Estimate of a statistic with its confidence interval
+type ConfidenceInterval struct {
+	Estimate   float64 `json,yaml:"estimate"`
+	Lower      float64 `json,yaml:"lower"`
+	Upper      float64 `json,yaml:"upper"`
+	Confidence float64 `json,yaml:"confidence"`
+}
+
+// Statistic of a sample, e.g. mean of latencies
+type Statistic func(values []float64) float64
+
+const (
+	defaultResamples  = 10000
+	defaultConfidence = 0.95
+	// Limit of leave-one-out jackknife samples for BCa acceleration; larger samples are jackknifed by groups
+	maxJackknifeGroups = 1000
+)
+
+// Mean value statistic
+func MeanStat(values []float64) float64 {
+	return mean(values)
+}
+
+// Median value statistic
+func MedianStat(values []float64) float64 {
+	return PercentileStat(50)(values)
+}
+
+// Percentile statistic with the given level in range [0, 100]
+func PercentileStat(level float64) Statistic {
+	return func(values []float64) float64 {
+		sorted := append([]float64{}, values...)
+		sort.Float64s(sorted)
+		return percentile(sorted, level)
+	}
+}
+
+// Calculates confidence interval of the statistic of values (e.g. `RunStats.Values`) with bootstrap resampling
+func Bootstrap(values []float64, stat Statistic, config BootstrapConfig) (ConfidenceInterval, error) {
+	return bootstrap([][]float64{values}, func(s [][]float64) float64 { return stat(s[0]) }, config)
+}
+
+// Calculates confidence interval of difference of the statistic of the first sample and the second one
+func BootstrapDifference(x1, x2 []float64, stat Statistic, config BootstrapConfig) (ConfidenceInterval, error) {
+	return bootstrap([][]float64{x1, x2}, func(s [][]float64) float64 { return stat(s[0]) - stat(s[1]) }, config)
+}
+
+// Calculates confidence interval of ratio of the statistic of the first sample to the second one
+func BootstrapRatio(x1, x2 []float64, stat Statistic, config BootstrapConfig) (ConfidenceInterval, error) {
+	return bootstrap([][]float64{x1, x2}, func(s [][]float64) float64 { return stat(s[0]) / stat(s[1]) }, config)
+}
+
+// Bootstraps statistic of several samples resampling each of them independently
+func bootstrap(samples [][]float64, stat func([][]float64) float64, config BootstrapConfig) (ConfidenceInterval, error) {
+	resamples, confidence := config.Resamples, config.Confidence
+	if resamples == 0 {
+		resamples = defaultResamples
+	}
+	if confidence == 0 {
+		confidence = defaultConfidence
+	}
+	if resamples < 0 || confidence < 0 || confidence >= 1 {
+		panic("number of resamples should be positive and confidence should be in (0, 1)")
+	}
+	for _, sample := range samples {
+		if len(sample) == 0 {
+			return ConfidenceInterval{}, ErrSampleSize
+		}
+	}
+
+	ret := ConfidenceInterval{Estimate: stat(samples), Confidence: confidence}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	resampled := make([][]float64, len(samples))
+	for i, sample := range samples {
+		resampled[i] = make([]float64, len(sample))
+	}
+	dist := make([]float64, resamples)
+	for r := range dist {
+		for i, sample := range samples {
+			for k := range resampled[i] {
+				resampled[i][k] = sample[rng.Intn(len(sample))]
+			}
+		}
+		dist[r] = stat(resampled)
+	}
+	sort.Float64s(dist)
+
+	alpha := (1 - confidence) / 2
+	lo, hi := alpha, 1-alpha
+	if config.Method == BCaInterval && dist[0] != dist[len(dist)-1] {
+		z0 := biasCorrection(dist, ret.Estimate)
+		a := acceleration(samples, stat)
+		lo = bcaLevel(z0, a, normalQuantile(alpha))
+		hi = bcaLevel(z0, a, normalQuantile(1-alpha))
+	}
+	ret.Lower = percentile(dist, 100*lo)
+	ret.Upper = percentile(dist, 100*hi)
+	return ret, nil
+}
+
+// Bias correction of BCa method - normal quantile of fraction of bootstrap estimates below the estimate
+func biasCorrection(sorted []float64, estimate float64) float64 {
+	below := sort.SearchFloat64s(sorted, estimate)
+	equal := sort.Search(len(sorted), func(i int) bool { return sorted[i] > estimate }) - below
+	n := float64(len(sorted))
+	fraction := (float64(below) + float64(equal)/2) / n
+	fraction = min(max(fraction, 1/(n+1)), n/(n+1))
+	return normalQuantile(fraction)
+}
+
+// Acceleration of BCa method from jackknife estimates. Each sample is jackknifed leaving out
+// one value or, for large samples, one of `maxJackknifeGroups` groups of values.
+func acceleration(samples [][]float64, stat func([][]float64) float64) float64 {
+	jack := make([]float64, 0)
+	reduced := make([][]float64, len(samples))
+	copy(reduced, samples)
+	for i, sample := range samples {
+		groups := min(len(sample), maxJackknifeGroups)
+		if groups < 2 {
+			continue
+		}
+		part := make([]float64, 0, len(sample))
+		for g := range groups {
+			from, to := g*len(sample)/groups, (g+1)*len(sample)/groups
+			part = append(append(part[:0], sample[:from]...), sample[to:]...)
+			reduced[i] = part
+			jack = append(jack, stat(reduced))
+		}
+		reduced[i] = sample
+	}
+	if len(jack) == 0 {
+		return 0
+	}
+
+	m := mean(jack)
+	num, den := 0.0, 0.0
+	for _, j := range jack {
+		d := m - j
+		num += d * d * d
+		den += d * d
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / (6 * math.Pow(den, 1.5))
+}
+
+// Adjusted quantile level of BCa interval
+func bcaLevel(z0, a, z float64) float64 {
+	return normalCdf(z0 + (z0+z)/(1-a*(z0+z)))
+}
+
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
@@ -0,0 +1,96 @@
+package perform
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func normalSample(n int, mean, sd float64, seed int64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	ret := make([]float64, n)
+	for i := range ret {
+		ret[i] = mean + sd*rng.NormFloat64()
+	}
+	return ret
+}
+
+func TestBootstrapMean(t *testing.T) {
+	assertT := assert.New(t)
+
+	values := normalSample(400, 10, 2, 1)
+	config := BootstrapConfig{Resamples: 2000, Seed: 42}
+	ci, err := Bootstrap(values, MeanStat, config)
+	assertT.NoError(err)
+
+	assertT.Equal(mean(values), ci.Estimate)
+	assertT.Equal(0.95, ci.Confidence)
+	assertT.Less(ci.Lower, ci.Estimate)
+	assertT.Greater(ci.Upper, ci.Estimate)
+	// Width of normal interval is 2*1.96*sd/sqrt(n)
+	stdErr := math.Sqrt(variance(values) / float64(len(values)))
+	assertT.InDelta(2*1.96*stdErr, ci.Upper-ci.Lower, 0.1*2*1.96*stdErr)
+
+	again, err := Bootstrap(values, MeanStat, config)
+	assertT.NoError(err)
+	assertT.Equal(ci, again)
+
+	config.Method = BCaInterval
+	bca, err := Bootstrap(values, MeanStat, config)
+	assertT.NoError(err)
+	assertT.InDelta(ci.Lower, bca.Lower, stdErr/2)
+	assertT.InDelta(ci.Upper, bca.Upper, stdErr/2)
+}
+
+func TestBootstrapPercentiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	values := normalSample(500, 10, 2, 2)
+	config := BootstrapConfig{Resamples: 1000, Confidence: 0.9, Method: BCaInterval}
+
+	median, err := Bootstrap(values, MedianStat, config)
+	assertT.NoError(err)
+	assertT.Equal(0.9, median.Confidence)
+	assertT.InDelta(10, median.Estimate, 0.5)
+	assertT.Less(median.Lower, median.Estimate)
+	assertT.Greater(median.Upper, median.Estimate)
+
+	p90, err := Bootstrap(values, PercentileStat(90), config)
+	assertT.NoError(err)
+	assertT.Greater(p90.Lower, median.Upper)
+	assertT.Less(p90.Lower, p90.Upper)
+}
+
+func TestBootstrapCompare(t *testing.T) {
+	assertT := assert.New(t)
+
+	x1 := normalSample(300, 10, 1, 3)
+	x2 := normalSample(300, 15, 1, 4)
+	config := BootstrapConfig{Resamples: 1000, Method: BCaInterval}
+
+	diff, err := BootstrapDifference(x1, x2, MeanStat, config)
+	assertT.NoError(err)
+	assertT.InDelta(mean(x1)-mean(x2), diff.Estimate, 1e-12)
+	assertT.Less(diff.Lower, -5.0)
+	assertT.Greater(diff.Upper, -5.0)
+
+	ratio, err := BootstrapRatio(x1, x2, MedianStat, config)
+	assertT.NoError(err)
+	assertT.Less(ratio.Lower, 2.0/3)
+	assertT.Greater(ratio.Upper, 2.0/3)
+	assertT.Less(ratio.Upper, 1.0)
+
+	_, err = BootstrapDifference(x1, nil, MeanStat, config)
+	assertT.ErrorIs(err, ErrSampleSize)
+	assertT.Panics(func() { _, _ = Bootstrap(x1, MeanStat, BootstrapConfig{Confidence: 1}) })
+}
+
+func TestBootstrapConstant(t *testing.T) {
+	assertT := assert.New(t)
+
+	ci, err := Bootstrap([]float64{3, 3, 3}, MeanStat, BootstrapConfig{Resamples: 100, Method: BCaInterval})
+	assertT.NoError(err)
+	assertT.Equal(ConfidenceInterval{Estimate: 3, Lower: 3, Upper: 3, Confidence: 0.95}, ci)
+}
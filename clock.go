@@ -0,0 +1,104 @@
+package perform
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aknopov/perform/tickcount"
+)
+
+// Calibration of the CPU tick counter against the monotonic clock
+type TickCalibration struct {
+	// Tick counter frequency
+	TicksPerNsec float64 `json,yaml:"ticks_per_nsec"`
+	// Ticks spent reading the counter; subtracted from each measurement
+	Overhead float64 `json,yaml:"overhead_ticks"`
+	// Relative standard deviation of the frequency between calibration rounds - lower is better
+	Deviation float64 `json,yaml:"deviation"`
+	// Number of calibration rounds
+	Rounds int `json,yaml:"rounds"`
+}
+
+// Error of calibration on platforms without usable tick counter
+var ErrTicksUnsupported = errors.New("tick counter is not supported")
+
+const (
+	calibrationRounds   = 5
+	calibrationInterval = 10 * time.Millisecond
+	overheadSamples     = 1001
+)
+
+// Measures runs with the CPU tick counter (`tickcount.TickCount`) instead of the monotonic clock.
+// This lowers clock reading overhead that dominates latencies of sub-microsecond tasks. The counter is calibrated
+// when the test starts; calibration is reported in `RunStats.TickClock`. Only task calls are timed with ticks -
+// waiting of open-loop runs for their start is measured with the monotonic clock. The monotonic clock is used
+// on platforms without tick counter.
+func WithTickClock() RunOption {
+	return func(c *runConfig) { c.tickClock = true }
+}
+
+// Calibrates tick counter frequency against the monotonic clock in "rounds" rounds of "interval" duration
+// and measures overhead of reading the counter
+func CalibrateTicks(rounds int, interval time.Duration) (TickCalibration, error) {
+	if rounds <= 0 || interval <= 0 {
+		panic("calibration rounds and interval should be positive")
+	}
+	if tickcount.TickCount() == 0 {
+		return TickCalibration{}, ErrTicksUnsupported
+	}
+
+	rates := make([]float64, rounds)
+	for i := range rates {
+		startTime := time.Now()
+		startTicks := tickcount.TickCount()
+		time.Sleep(interval)
+		endTicks := tickcount.TickCount()
+		rates[i] = float64(endTicks-startTicks) / float64(time.Since(startTime))
+	}
+
+	ret := TickCalibration{TicksPerNsec: mean(rates), Overhead: tickOverhead(), Rounds: rounds}
+	if ret.TicksPerNsec <= 0 {
+		return TickCalibration{}, ErrTicksUnsupported
+	}
+	ret.Deviation = math.Sqrt(variance(rates)) / ret.TicksPerNsec
+	return ret, nil
+}
+
+// Median number of ticks between two consecutive counter reads
+func tickOverhead() float64 {
+	samples := make([]float64, overheadSamples)
+	for i := range samples {
+		t0 := tickcount.TickCount()
+		t1 := tickcount.TickCount()
+		samples[i] = float64(t1 - t0)
+	}
+	sort.Float64s(samples)
+	return samples[len(samples)/2]
+}
+
+// Converts measured ticks to time excluding counter reading overhead
+func (c *TickCalibration) duration(ticks uint64) time.Duration {
+	return time.Duration(max(float64(ticks)-c.Overhead, 0) / c.TicksPerNsec)
+}
+
+// Reads the tick counter when tick clock is in use
+func (c *TickCalibration) read() uint64 {
+	if c == nil {
+		return 0
+	}
+	return tickcount.TickCount()
+}
+
+// Calibrates tick counter if requested; returns nil when it is not requested or not supported
+func newTickClock(config runConfig) *TickCalibration {
+	if !config.tickClock {
+		return nil
+	}
+	calibration, err := CalibrateTicks(calibrationRounds, calibrationInterval)
+	if err != nil {
+		return nil
+	}
+	return &calibration
+}
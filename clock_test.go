@@ -0,0 +1,50 @@
+package perform
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aknopov/perform/tickcount"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalibrateTicks(t *testing.T) {
+	assertT := assert.New(t)
+
+	calibration, err := CalibrateTicks(3, 5*time.Millisecond)
+	if tickcount.TickCount() == 0 {
+		assertT.ErrorIs(err, ErrTicksUnsupported)
+		return
+	}
+
+	assertT.NoError(err)
+	assertT.Greater(calibration.TicksPerNsec, 0.0)
+	assertT.GreaterOrEqual(calibration.Overhead, 0.0)
+	assertT.Less(calibration.Deviation, 0.5)
+	assertT.Equal(3, calibration.Rounds)
+
+	assertT.Equal(time.Duration(0), calibration.duration(0))
+	ticks := uint64(calibration.Overhead + 1000*calibration.TicksPerNsec)
+	assertT.InDelta(float64(time.Microsecond), float64(calibration.duration(ticks)), 1)
+
+	assertT.Panics(func() { _, _ = CalibrateTicks(0, time.Millisecond) })
+}
+
+func TestTickClock(t *testing.T) {
+	assertT := assert.New(t)
+	if tickcount.TickCount() == 0 {
+		t.Skip("tick counter is not supported")
+	}
+
+	stats := RunTestContext(context.Background(), []ContextTask{sleepTask}, TotalTests, Parallel, WithTickClock())
+
+	oneStat := stats[0]
+	assertT.NotNil(oneStat.TickClock)
+	assertT.Equal(TotalTests, oneStat.Count)
+	assertT.GreaterOrEqual(oneStat.MinTime, 0.9*float64(SleepTime)/msecFctr)
+	assertT.Less(oneStat.MedTime, 1.5*float64(SleepTime)/msecFctr)
+
+	stats = RunTestContext(context.Background(), []ContextTask{sleepTask}, TotalTests, Parallel)
+	assertT.Nil(stats[0].TickClock)
+}
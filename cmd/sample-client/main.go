@@ -49,9 +49,8 @@ func main() {
 
 	waitServer(requestUrl, 5*time.Minute)
 
-	startTime := time.Now()
-	stats := perform.RunTest([]perform.TestTask{task}, *totalTests, *concur)
-	elapsedTime := time.Since(startTime)
+	var report perform.RunReport
+	stats := perform.RunTest([]perform.TestTask{task}, *totalTests, *concur, perform.WithReport(&report))
 
 	//nolint:errcheck
 	sendOneRequest(requestUrl, []byte(quitReq))
@@ -59,13 +58,22 @@ func main() {
 	logger.Info().Msgf("Test finished for the length %d:", pwdReq.Length)
 	logger.Info().Int("  num tests", stats[0].Count).Send()
 	logger.Info().Int("  num concur", *concur).Send()
+	logger.Info().Int("  max concur", report.MaxConcurrency).Send()
 	logger.Info().Int("  num failures", stats[0].Fails).Send()
-	logger.Info().Dur("  duration (ms)", elapsedTime).Send()
+	logger.Info().Dur("  duration (ms)", report.WallTime).Send()
+	logger.Info().Float64("  throughput (1/s)", report.Throughput).Send()
 	logger.Info().Float64("  max (ms)", stats[0].MaxTime).Send()
 	logger.Info().Float64("  med (ms)", stats[0].MedTime).Send()
 	logger.Info().Float64("  min (ms)", stats[0].MinTime).Send()
 	logger.Info().Float64("  avg (ms)", stats[0].AvgTime).Send()
 	logger.Info().Float64("  stdev (ms)", stats[0].StdDev).Send()
+	for _, p := range stats[0].Percentiles {
+		logger.Info().Float64(fmt.Sprintf("  p%g (ms)", p.Level), p.Value).Send()
+	}
+
+	logger.Info().Msgf("Environment: %s %s/%s, GOMAXPROCS=%d, NumCPU=%d, host %q, revision %q",
+		report.Env.GoVersion, report.Env.OS, report.Env.Arch, report.Env.GoMaxProcs, report.Env.NumCPU,
+		report.Env.Hostname, report.Env.GitRevision)
 
 	if *printRaw {
 		fmt.Printf("        Raw test durations (ms):\n")
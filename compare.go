@@ -0,0 +1,139 @@
+package perform
+
+import "fmt"
+
+// Kind of hypothesis test used by `CalcPvals`
+type TestKind int
+
+const (
+	// Two-sample Student's t-test - assumes equal variances of latencies
+	StudentTest TestKind = iota
+	// Two-sample Welch's t-test - doesn't assume equal variances
+	WelchTest
+	// Paired t-test on raw values of runs; run counts should be equal
+	PairedTest
+	// Non-parametric Mann-Whitney U test on raw values of runs
+	RankTest
+)
+
+func (k TestKind) String() string {
+	switch k {
+	case StudentTest:
+		return "Student"
+	case WelchTest:
+		return "Welch"
+	case PairedTest:
+		return "paired"
+	case RankTest:
+		return "Mann-Whitney"
+	default:
+		return fmt.Sprintf("TestKind(%d)", int(k))
+	}
+}
+
+// Result of comparison of one task in two series of tests
+type PvalResult struct {
+	N1 int `json,yaml:"n1"`
+	N2 int `json,yaml:"n2"`
+	// t-statistic; U-statistic for the rank test
+	T float64 `json,yaml:"t"`
+	// Degrees of freedom of t-distribution; zero for the rank test
+	DoF float64 `json,yaml:"dof"`
+	// Raw p-value for the alternative hypothesis
+	P float64 `json,yaml:"p"`
+	// P-value adjusted for multiple comparisons across tasks; equals to `P` without correction
+	AdjustedP float64 `json,yaml:"adjusted_p"`
+	// Null hypothesis is rejected - adjusted p-value is less than the significance level
+	Reject bool `json,yaml:"reject"`
+	// Effect sizes of the difference
+	Effect EffectSize `json,yaml:"effect"`
+	// Difference is both statistically significant and not less than minimum detectable effect
+	Significant bool `json,yaml:"significant"`
+}
+
+// Option of comparison of test series
+type PvalOption func(*pvalConfig)
+
+type pvalConfig struct {
+	kind          TestKind
+	alt           LocationHypothesis
+	alpha         float64
+	effectMeasure EffectMeasure
+	minEffect     float64
+	correction    Correction
+}
+
+// Selects hypothesis test; default is `StudentTest`
+func WithTestKind(kind TestKind) PvalOption {
+	return func(c *pvalConfig) { c.kind = kind }
+}
+
+// Sets alternative hypothesis about location of the first series relative to the second one;
+// default is `LocationGreater`
+func WithAlternative(alt LocationHypothesis) PvalOption {
+	return func(c *pvalConfig) { c.alt = alt }
+}
+
+// Sets significance level of decisions; default is 0.05
+func WithSignificance(alpha float64) PvalOption {
+	if alpha <= 0 || alpha >= 1 {
+		panic("significance level should be in (0, 1)")
+	}
+	return func(c *pvalConfig) { c.alpha = alpha }
+}
+
+func newPvalConfig(opts []PvalOption) pvalConfig {
+	config := pvalConfig{kind: StudentTest, alt: LocationGreater, alpha: 0.05}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// Compares statistics of one task with the configured test and evaluates effect size
+func (c pvalConfig) compare(rs1, rs2 RunStats) (PvalResult, error) {
+	res, err := c.test(rs1, rs2)
+	if err != nil {
+		return res, err
+	}
+	res.Effect = CalcEffectSize(rs1, rs2)
+	return res, nil
+}
+
+// Adjusts p-values of all tasks and makes decisions
+func (c pvalConfig) decide(results []PvalResult) {
+	pvals := make([]float64, len(results))
+	for i := range results {
+		pvals[i] = results[i].P
+	}
+	for i, p := range AdjustPvals(pvals, c.correction) {
+		results[i].AdjustedP = p
+		results[i].Reject = p < c.alpha
+		results[i].Significant = results[i].Reject && c.isPractical(results[i].Effect)
+	}
+}
+
+func (c pvalConfig) test(rs1, rs2 RunStats) (PvalResult, error) {
+	var tRes *TTestResult
+	var err error
+	switch c.kind {
+	case StudentTest:
+		tRes, err = TwoSampleTTest(timeStat2Tstat(rs1), timeStat2Tstat(rs2), c.alt)
+	case WelchTest:
+		tRes, err = TwoSampleWelchTTest(timeStat2Tstat(rs1), timeStat2Tstat(rs2), c.alt)
+	case PairedTest:
+		tRes, err = PairedTTest(rs1.Values, rs2.Values, c.alt)
+	case RankTest:
+		uRes, err := MannWhitneyUTest(rs1.Values, rs2.Values, c.alt)
+		if err != nil {
+			return PvalResult{}, err
+		}
+		return PvalResult{N1: uRes.N1, N2: uRes.N2, T: uRes.U, P: uRes.P}, nil
+	default:
+		panic(fmt.Sprintf("unknown test kind %d", c.kind))
+	}
+	if err != nil {
+		return PvalResult{}, err
+	}
+	return PvalResult{N1: tRes.N1, N2: tRes.N2, T: tRes.T, DoF: tRes.DoF, P: tRes.P}, nil
+}
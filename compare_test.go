@@ -0,0 +1,60 @@
+package perform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcPvalsKinds(t *testing.T) {
+	assertT := assert.New(t)
+
+	slow, fast := slowStats, fastStats
+
+	student, err := CalcPvals([]RunStats{slow}, []RunStats{fast})
+	assertT.NoError(err)
+	assertT.InDelta(5.0, student[0].T, 1e-9)
+	assertT.Equal(8.0, student[0].DoF)
+	assertT.Less(student[0].P, 0.001)
+	assertT.True(student[0].Reject)
+
+	welch, err := CalcPvals([]RunStats{slow}, []RunStats{fast}, WithTestKind(WelchTest))
+	assertT.NoError(err)
+	assertT.InDelta(student[0].T, welch[0].T, 1e-9)
+	assertT.InDelta(8.0, welch[0].DoF, 1e-9)
+
+	paired, err := CalcPvals([]RunStats{slow}, []RunStats{fast}, WithTestKind(PairedTest), WithAlternative(LocationLess))
+	assertT.ErrorContains(err, "zero variance")
+	assertT.Nil(paired)
+
+	slow.Values = []float64{6, 7, 8, 9, 11}
+	paired, err = CalcPvals([]RunStats{fast}, []RunStats{slow}, WithTestKind(PairedTest), WithAlternative(LocationLess))
+	assertT.NoError(err)
+	assertT.Equal(4.0, paired[0].DoF)
+	assertT.Less(paired[0].T, 0.0)
+	assertT.True(paired[0].Reject)
+
+	rank, err := CalcPvals([]RunStats{fast}, []RunStats{slow}, WithTestKind(RankTest), WithAlternative(LocationLess))
+	assertT.NoError(err)
+	assertT.Equal(0.0, rank[0].T)
+	assertT.Equal(0.0, rank[0].DoF)
+	assertT.True(rank[0].Reject)
+
+	differs, err := CalcPvals([]RunStats{slow}, []RunStats{fast}, WithAlternative(LocationDiffers),
+		WithSignificance(1e-6))
+	assertT.NoError(err)
+	assertT.InDelta(2*student[0].P, differs[0].P, 1e-12)
+	assertT.False(differs[0].Reject)
+
+	_, err = CalcPvals([]RunStats{{Count: 5}}, []RunStats{fast}, WithTestKind(RankTest))
+	assertT.ErrorIs(err, ErrSampleSize)
+	assertT.Panics(func() { WithSignificance(0) })
+}
+
+func TestTestKindString(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal("Welch", WelchTest.String())
+	assertT.Equal("Mann-Whitney", RankTest.String())
+	assertT.Equal("TestKind(7)", TestKind(7).String())
+}
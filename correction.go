@@ -0,0 +1,78 @@
+package perform
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Method of p-values adjustment for multiple comparisons
+type Correction int
+
+const (
+	// P-values are not adjusted
+	NoCorrection Correction = iota
+	// Bonferroni correction - controls family-wise error rate; conservative
+	Bonferroni
+	// Holm step-down correction - controls family-wise error rate; uniformly more powerful than Bonferroni
+	Holm
+	// Benjamini-Hochberg correction - controls false discovery rate
+	BenjaminiHochberg
+)
+
+func (c Correction) String() string {
+	switch c {
+	case NoCorrection:
+		return "none"
+	case Bonferroni:
+		return "Bonferroni"
+	case Holm:
+		return "Holm"
+	case BenjaminiHochberg:
+		return "Benjamini-Hochberg"
+	default:
+		return fmt.Sprintf("Correction(%d)", int(c))
+	}
+}
+
+// Adjusts p-values of a family of comparisons. Null hypotheses are rejected when adjusted p-values
+// are less than the significance level.
+func AdjustPvals(pvals []float64, method Correction) []float64 {
+	m := len(pvals)
+	adjusted := make([]float64, m)
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return pvals[order[i]] < pvals[order[j]] })
+
+	switch method {
+	case NoCorrection:
+		copy(adjusted, pvals)
+	case Bonferroni:
+		for i, p := range pvals {
+			adjusted[i] = min(float64(m)*p, 1)
+		}
+	case Holm:
+		running := 0.0
+		for rank, i := range order {
+			running = max(running, min(float64(m-rank)*pvals[i], 1))
+			adjusted[i] = running
+		}
+	case BenjaminiHochberg:
+		running := 1.0
+		for rank := m - 1; rank >= 0; rank-- {
+			i := order[rank]
+			running = min(running, float64(m)/float64(rank+1)*pvals[i])
+			adjusted[i] = running
+		}
+	default:
+		panic(fmt.Sprintf("unknown correction %d", method))
+	}
+	return adjusted
+}
+
+// Adjusts p-values of tasks for multiple comparisons; decisions are made on adjusted p-values.
+// Default is `NoCorrection`.
+func WithCorrection(method Correction) PvalOption {
+	return func(c *pvalConfig) { c.correction = method }
+}
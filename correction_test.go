@@ -0,0 +1,59 @@
+package perform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertSlicesClose(assertT *assert.Assertions, expected, actual []float64) {
+	assertT.Equal(len(expected), len(actual))
+	for i := range expected {
+		assertT.InDelta(expected[i], actual[i], 1e-12)
+	}
+}
+
+func TestAdjustPvals(t *testing.T) {
+	assertT := assert.New(t)
+
+	pvals := []float64{0.01, 0.02, 0.03, 0.04, 0.05}
+	assertSlicesClose(assertT, pvals, AdjustPvals(pvals, NoCorrection))
+	assertSlicesClose(assertT, []float64{0.05, 0.1, 0.15, 0.2, 0.25}, AdjustPvals(pvals, Bonferroni))
+	assertSlicesClose(assertT, []float64{0.05, 0.08, 0.09, 0.09, 0.09}, AdjustPvals(pvals, Holm))
+	assertSlicesClose(assertT, []float64{0.05, 0.05, 0.05, 0.05, 0.05}, AdjustPvals(pvals, BenjaminiHochberg))
+
+	unordered := []float64{0.04, 0.001, 0.03}
+	assertSlicesClose(assertT, []float64{0.06, 0.003, 0.06}, AdjustPvals(unordered, Holm))
+	assertSlicesClose(assertT, []float64{0.04, 0.003, 0.04}, AdjustPvals(unordered, BenjaminiHochberg))
+	assertSlicesClose(assertT, []float64{0.12, 0.003, 0.09}, AdjustPvals(unordered, Bonferroni))
+	assertSlicesClose(assertT, []float64{1, 1}, AdjustPvals([]float64{0.6, 0.7}, Bonferroni))
+	assertT.Equal(0, len(AdjustPvals(nil, Holm)))
+
+	assertT.Panics(func() { AdjustPvals(pvals, Correction(9)) })
+	assertT.Equal("Benjamini-Hochberg", BenjaminiHochberg.String())
+}
+
+func TestCalcPvalsCorrection(t *testing.T) {
+	assertT := assert.New(t)
+
+	close1 := RunStats{Count: 50, AvgTime: 100, StdDev: 5}
+	close2 := RunStats{Count: 50, AvgTime: 101, StdDev: 5}
+	stats1 := []RunStats{close1, slowStats, fastStats}
+	stats2 := []RunStats{close2, fastStats, fastStats}
+
+	raw, err := CalcPvals(stats1, stats2)
+	assertT.NoError(err)
+	for _, res := range raw {
+		assertT.Equal(res.P, res.AdjustedP)
+	}
+
+	adjusted, err := CalcPvals(stats1, stats2, WithCorrection(Bonferroni))
+	assertT.NoError(err)
+	for i, res := range adjusted {
+		assertT.Equal(raw[i].P, res.P)
+		assertT.InDelta(min(3*res.P, 1), res.AdjustedP, 1e-12)
+		assertT.Equal(res.AdjustedP < 0.05, res.Reject)
+	}
+	assertT.True(adjusted[1].Reject)
+	assertT.False(adjusted[2].Reject)
+}
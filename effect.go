@@ -0,0 +1,121 @@
+package perform
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Effect sizes of difference between latencies of two test series. Positive values mean
+// that latencies of the first series are greater.
+type EffectSize struct {
+	// Difference of means in units of pooled standard deviation
+	CohensD float64 `json,yaml:"cohens_d"`
+	// Cohen's d corrected for small sample bias
+	HedgesG float64 `json,yaml:"hedges_g"`
+	// Probability that a value of the first series is greater than a value of the second one minus probability
+	// of the opposite; in range [-1, 1]. Calculated from raw values - NaN if they are not available.
+	CliffsDelta float64 `json,yaml:"cliffs_delta"`
+	// Relative difference of medians - (med1 - med2) / med2
+	MedianDelta float64 `json,yaml:"median_delta"`
+}
+
+// Measure of effect size
+type EffectMeasure int
+
+const (
+	CohensD EffectMeasure = iota
+	HedgesG
+	CliffsDelta
+	MedianDelta
+)
+
+func (m EffectMeasure) String() string {
+	switch m {
+	case CohensD:
+		return "Cohen's d"
+	case HedgesG:
+		return "Hedges' g"
+	case CliffsDelta:
+		return "Cliff's delta"
+	case MedianDelta:
+		return "median delta"
+	default:
+		return fmt.Sprintf("EffectMeasure(%d)", int(m))
+	}
+}
+
+// Calculates effect sizes of difference between statistics of two runs of a task
+func CalcEffectSize(rs1, rs2 RunStats) EffectSize {
+	n1, n2 := float64(rs1.Count), float64(rs2.Count)
+	pooled := math.Sqrt(((n1-1)*rs1.StdDev*rs1.StdDev + (n2-1)*rs2.StdDev*rs2.StdDev) / (n1 + n2 - 2))
+	d := (rs1.AvgTime - rs2.AvgTime) / pooled
+
+	return EffectSize{
+		CohensD:     d,
+		HedgesG:     d * (1 - 3/(4*(n1+n2)-9)),
+		CliffsDelta: cliffsDelta(rs1.Values, rs2.Values),
+		MedianDelta: (rs1.MedTime - rs2.MedTime) / rs2.MedTime,
+	}
+}
+
+// Value of the given measure
+func (e EffectSize) Value(measure EffectMeasure) float64 {
+	switch measure {
+	case CohensD:
+		return e.CohensD
+	case HedgesG:
+		return e.HedgesG
+	case CliffsDelta:
+		return e.CliffsDelta
+	case MedianDelta:
+		return e.MedianDelta
+	default:
+		panic(fmt.Sprintf("unknown effect measure %d", measure))
+	}
+}
+
+func cliffsDelta(x1, x2 []float64) float64 {
+	if len(x1) == 0 || len(x2) == 0 {
+		return math.NaN()
+	}
+
+	sorted := append([]float64{}, x2...)
+	sort.Float64s(sorted)
+	dominance := 0
+	for _, x := range x1 {
+		less := sort.SearchFloat64s(sorted, x)
+		greater := len(sorted) - sort.Search(len(sorted), func(i int) bool { return sorted[i] > x })
+		dominance += less - greater
+	}
+	return float64(dominance) / float64(len(x1)*len(x2))
+}
+
+// Sets minimum detectable effect - differences smaller than the threshold are not practically significant
+// even if they are statistically significant. Effect direction follows the alternative hypothesis;
+// e.g. `WithMinEffect(MedianDelta, 0.05)` flags only tasks whose median latency grew by 5% or more.
+func WithMinEffect(measure EffectMeasure, threshold float64) PvalOption {
+	if threshold < 0 {
+		panic("effect threshold should not be negative")
+	}
+	return func(c *pvalConfig) {
+		c.effectMeasure = measure
+		c.minEffect = threshold
+	}
+}
+
+// Checks whether the effect reaches minimum detectable effect in direction of the alternative hypothesis
+func (c pvalConfig) isPractical(effect EffectSize) bool {
+	if c.minEffect == 0 {
+		return true
+	}
+	value := effect.Value(c.effectMeasure)
+	switch c.alt {
+	case LocationGreater:
+		return value >= c.minEffect
+	case LocationLess:
+		return value <= -c.minEffect
+	default:
+		return math.Abs(value) >= c.minEffect
+	}
+}
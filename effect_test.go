@@ -0,0 +1,71 @@
+package perform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	slowStats = RunStats{Count: 5, AvgTime: 8, MedTime: 8, StdDev: 1.5811388300841898, Values: []float64{6, 7, 8, 9, 10}}
+	fastStats = RunStats{Count: 5, AvgTime: 3, MedTime: 3, StdDev: 1.5811388300841898, Values: []float64{1, 2, 3, 4, 5}}
+)
+
+func TestCalcEffectSize(t *testing.T) {
+	assertT := assert.New(t)
+
+	effect := CalcEffectSize(slowStats, fastStats)
+	d := 5 / 1.5811388300841898
+	assertT.InDelta(d, effect.CohensD, 1e-12)
+	assertT.InDelta(d*(1-3.0/31), effect.HedgesG, 1e-12)
+	assertT.Equal(1.0, effect.CliffsDelta)
+	assertT.InDelta(5.0/3, effect.MedianDelta, 1e-12)
+
+	reverse := CalcEffectSize(fastStats, slowStats)
+	assertT.InDelta(-d, reverse.CohensD, 1e-12)
+	assertT.Equal(-1.0, reverse.CliffsDelta)
+	assertT.InDelta(-5.0/8, reverse.Value(MedianDelta), 1e-12)
+
+	noValues := CalcEffectSize(RunStats{Count: 5, AvgTime: 2, MedTime: 2, StdDev: 1}, fastStats)
+	assertT.True(math.IsNaN(noValues.CliffsDelta))
+}
+
+func TestCliffsDeltaTies(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal(-0.75, cliffsDelta([]float64{1, 2}, []float64{2, 3}))
+	assertT.Equal(0.0, cliffsDelta([]float64{2, 2}, []float64{2}))
+}
+
+func TestMinEffect(t *testing.T) {
+	assertT := assert.New(t)
+
+	res, err := CalcPvals([]RunStats{slowStats}, []RunStats{fastStats})
+	assertT.NoError(err)
+	assertT.True(res[0].Reject)
+	assertT.True(res[0].Significant)
+	assertT.Equal(1.0, res[0].Effect.CliffsDelta)
+
+	res, err = CalcPvals([]RunStats{slowStats}, []RunStats{fastStats}, WithMinEffect(MedianDelta, 2))
+	assertT.NoError(err)
+	assertT.True(res[0].Reject)
+	assertT.False(res[0].Significant)
+
+	res, err = CalcPvals([]RunStats{slowStats}, []RunStats{fastStats}, WithMinEffect(MedianDelta, 1))
+	assertT.NoError(err)
+	assertT.True(res[0].Significant)
+
+	res, err = CalcPvals([]RunStats{fastStats}, []RunStats{slowStats}, WithAlternative(LocationLess),
+		WithMinEffect(CohensD, 3))
+	assertT.NoError(err)
+	assertT.True(res[0].Significant)
+
+	res, err = CalcPvals([]RunStats{fastStats}, []RunStats{slowStats}, WithAlternative(LocationDiffers),
+		WithMinEffect(HedgesG, 3))
+	assertT.NoError(err)
+	assertT.False(res[0].Significant)
+
+	assertT.Panics(func() { WithMinEffect(CohensD, -1) })
+	assertT.Equal("Cliff's delta", CliffsDelta.String())
+}
@@ -0,0 +1,121 @@
+package perform
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Equivalence margin - largest difference of mean latencies considered negligible
+type EquivalenceMargin struct {
+	value    float64
+	relative bool
+}
+
+// Result of equivalence test of one task
+type TOSTResult struct {
+	// Absolute margin in milliseconds
+	Margin float64 `json,yaml:"margin"`
+	// Difference of means of the first and the second series
+	Diff float64 `json,yaml:"diff"`
+	// t-statistics of tests against the lower (-margin) and the upper (+margin) bounds
+	TLower float64 `json,yaml:"t_lower"`
+	TUpper float64 `json,yaml:"t_upper"`
+	// Degrees of freedom of t-distribution
+	DoF float64 `json,yaml:"dof"`
+	// P-value of equivalence - the larger p-value of two one-sided tests
+	P float64 `json,yaml:"p"`
+	// P-value adjusted for multiple comparisons across tasks
+	AdjustedP float64 `json,yaml:"adjusted_p"`
+	// Equivalence is established at the significance level
+	Equivalent bool `json,yaml:"equivalent"`
+}
+
+// Error of equivalence test with a test kind that is not based on t-distribution
+var ErrUnsupportedTest = errors.New("test kind is not supported")
+
+// Margin in milliseconds
+func AbsoluteMargin(msec float64) EquivalenceMargin {
+	if msec <= 0 {
+		panic("equivalence margin should be positive")
+	}
+	return EquivalenceMargin{value: msec}
+}
+
+// Margin in percents of mean latency of the second (baseline) series
+func RelativeMargin(percent float64) EquivalenceMargin {
+	if percent <= 0 {
+		panic("equivalence margin should be positive")
+	}
+	return EquivalenceMargin{value: percent / 100, relative: true}
+}
+
+// Tests equivalence of latencies in two series of tests with two one-sided tests (TOST) procedure.
+// For each task it tests null hypotheses that the difference of mean latencies is below -margin or above
+// +margin; equivalence is established when both are rejected. Unlike a large p-value of `CalcPvals`
+// this proves absence of regression within the margin.
+//
+// Options select test kind (Student's, Welch's or paired t-test), significance level and multiple-comparison
+// correction; the alternative hypothesis and minimum effect options are not used.
+func CalcEquivalence(stats1, stats2 []RunStats, margin EquivalenceMargin, opts ...PvalOption) ([]TOSTResult, error) {
+	if len(stats1) != len(stats2) {
+		return nil, errors.New("different size of tasks")
+	}
+
+	config := newPvalConfig(opts)
+	results := make([]TOSTResult, 0, len(stats1))
+	pvals := make([]float64, 0, len(stats1))
+	for i := range stats1 {
+		res, err := config.tost(stats1[i], stats2[i], margin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid statistics data in test #%d: %w", i, err)
+		}
+		results = append(results, res)
+		pvals = append(pvals, res.P)
+	}
+
+	for i, p := range AdjustPvals(pvals, config.correction) {
+		results[i].AdjustedP = p
+		results[i].Equivalent = p < config.alpha
+	}
+	return results, nil
+}
+
+func (c pvalConfig) tost(rs1, rs2 RunStats, margin EquivalenceMargin) (TOSTResult, error) {
+	delta := margin.value
+	if margin.relative {
+		delta *= math.Abs(rs2.AvgTime)
+	}
+
+	lower, err := c.shiftedTest(rs1, rs2, delta, LocationGreater)
+	if err != nil {
+		return TOSTResult{}, err
+	}
+	upper, err := c.shiftedTest(rs1, rs2, -delta, LocationLess)
+	if err != nil {
+		return TOSTResult{}, err
+	}
+
+	return TOSTResult{Margin: delta, Diff: rs1.AvgTime - rs2.AvgTime, TLower: lower.T, TUpper: upper.T,
+		DoF: lower.DoF, P: max(lower.P, upper.P)}, nil
+}
+
+// One-sided t-test of the first series shifted by "shift" against the second one
+func (c pvalConfig) shiftedTest(rs1, rs2 RunStats, shift float64, alt LocationHypothesis) (*TTestResult, error) {
+	x1, x2 := timeStat2Tstat(rs1), timeStat2Tstat(rs2)
+	x1.mean += shift
+	switch c.kind {
+	case StudentTest:
+		return TwoSampleTTest(x1, x2, alt)
+	case WelchTest:
+		return TwoSampleWelchTTest(x1, x2, alt)
+	case PairedTest:
+		shifted := make([]float64, len(rs1.Values))
+		for i, v := range rs1.Values {
+			shifted[i] = v + shift
+		}
+		return PairedTTest(shifted, rs2.Values, alt)
+	default:
+		return nil, ErrUnsupportedTest
+	}
+}
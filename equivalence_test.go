@@ -0,0 +1,72 @@
+package perform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	baseStats = RunStats{Count: 50, AvgTime: 101, StdDev: 5}
+	newStats  = RunStats{Count: 50, AvgTime: 100, StdDev: 5}
+)
+
+func TestCalcEquivalence(t *testing.T) {
+	assertT := assert.New(t)
+
+	res, err := CalcEquivalence([]RunStats{newStats}, []RunStats{baseStats}, AbsoluteMargin(3))
+	assertT.NoError(err)
+	assertT.Equal(3.0, res[0].Margin)
+	assertT.Equal(-1.0, res[0].Diff)
+	assertT.InDelta(2.0, res[0].TLower, 1e-12)
+	assertT.InDelta(-4.0, res[0].TUpper, 1e-12)
+	assertT.Equal(98.0, res[0].DoF)
+	assertT.InDelta(0.0241, res[0].P, 5e-4)
+	assertT.Equal(res[0].P, res[0].AdjustedP)
+	assertT.True(res[0].Equivalent)
+
+	res, err = CalcEquivalence([]RunStats{newStats}, []RunStats{baseStats}, AbsoluteMargin(1.5))
+	assertT.NoError(err)
+	assertT.Greater(res[0].P, 0.3)
+	assertT.False(res[0].Equivalent)
+
+	res, err = CalcEquivalence([]RunStats{newStats}, []RunStats{baseStats}, RelativeMargin(3), WithTestKind(WelchTest))
+	assertT.NoError(err)
+	assertT.InDelta(3.03, res[0].Margin, 1e-12)
+	assertT.True(res[0].Equivalent)
+
+	res, err = CalcEquivalence([]RunStats{newStats, newStats, newStats}, []RunStats{baseStats, baseStats, baseStats},
+		AbsoluteMargin(3), WithCorrection(Bonferroni))
+	assertT.NoError(err)
+	assertT.InDelta(3*res[0].P, res[0].AdjustedP, 1e-12)
+	assertT.False(res[0].Equivalent)
+}
+
+func TestCalcEquivalencePaired(t *testing.T) {
+	assertT := assert.New(t)
+
+	res, err := CalcEquivalence([]RunStats{slowStats}, []RunStats{slowStats}, AbsoluteMargin(1),
+		WithTestKind(PairedTest))
+	assertT.ErrorIs(err, ErrZeroVariance)
+	assertT.Nil(res)
+
+	shifted := slowStats
+	shifted.Values = []float64{6.1, 6.9, 8.1, 8.9, 10}
+	res, err = CalcEquivalence([]RunStats{shifted}, []RunStats{slowStats}, AbsoluteMargin(1), WithTestKind(PairedTest))
+	assertT.NoError(err)
+	assertT.Equal(4.0, res[0].DoF)
+	assertT.True(res[0].Equivalent)
+}
+
+func TestCalcEquivalenceErrors(t *testing.T) {
+	assertT := assert.New(t)
+
+	_, err := CalcEquivalence([]RunStats{newStats}, []RunStats{baseStats}, AbsoluteMargin(3), WithTestKind(RankTest))
+	assertT.ErrorIs(err, ErrUnsupportedTest)
+
+	_, err = CalcEquivalence([]RunStats{newStats}, nil, AbsoluteMargin(3))
+	assertT.ErrorContains(err, "different size of tasks")
+
+	assertT.Panics(func() { AbsoluteMargin(0) })
+	assertT.Panics(func() { RelativeMargin(-1) })
+}
@@ -0,0 +1,93 @@
+package perform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Group of failed runs with the same error class
+type FailureGroup struct {
+	Class   string   `json,yaml:"class"`
+	Count   int      `json,yaml:"count"`
+	Samples []string `json,yaml:"samples"` // few distinct error messages
+}
+
+// Function that maps errors to class names
+type ErrorClassifier func(err error) string
+
+// Maximal number of distinct messages kept in a failure group
+const maxFailureSamples = 3
+
+// Error of a run aborted because of run timeout
+var ErrRunTimeout = errors.New("run timed out")
+
+// Sentinel errors that are always recognized
+var defaultErrorClasses = []error{ErrRunTimeout, context.DeadlineExceeded, context.Canceled}
+
+// Groups errors matching any of sentinel errors (via `errors.Is`) under the sentinel message.
+// Run timeout, context cancellation and deadline errors are recognized by default.
+func WithErrorClasses(sentinels ...error) RunOption {
+	return func(c *runConfig) { c.errorClasses = append(c.errorClasses, sentinels...) }
+}
+
+// Sets classifier for errors that do not match sentinel errors; default is `ClassifyByType`
+func WithErrorClassifier(classifier ErrorClassifier) RunOption {
+	return func(c *runConfig) { c.classifier = classifier }
+}
+
+// Keeps times of failed runs in `RunStats.FailValues` and excludes them from other statistics,
+// so that e.g. timeouts do not distort distribution of successful runs
+func WithSeparateFailures() RunOption {
+	return func(c *runConfig) { c.separateFails = true }
+}
+
+// Classifies errors by their dynamic type
+func ClassifyByType(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+// Classifies errors by their message
+func ClassifyByMessage(err error) string {
+	return err.Error()
+}
+
+func (c *runConfig) classifyError(err error) string {
+	for _, sentinel := range c.errorClasses {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	for _, sentinel := range defaultErrorClasses {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	if c.classifier != nil {
+		return c.classifier(err)
+	}
+	return ClassifyByType(err)
+}
+
+func addFailure(groups []FailureGroup, class string, msg string) []FailureGroup {
+	for i := range groups {
+		if groups[i].Class == class {
+			groups[i].Count++
+			groups[i].Samples = addSample(groups[i].Samples, msg)
+			return groups
+		}
+	}
+	return append(groups, FailureGroup{Class: class, Count: 1, Samples: []string{msg}})
+}
+
+func addSample(samples []string, msg string) []string {
+	if len(samples) >= maxFailureSamples {
+		return samples
+	}
+	for _, s := range samples {
+		if s == msg {
+			return samples
+		}
+	}
+	return append(samples, msg)
+}
@@ -0,0 +1,102 @@
+package perform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type codeError struct {
+	code int
+}
+
+func (e codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+var errSentinel = errors.New("sentinel")
+
+func TestClassifyError(t *testing.T) {
+	assertT := assert.New(t)
+
+	config := newRunConfig([]RunOption{WithErrorClasses(errSentinel)})
+	assertT.Equal("sentinel", config.classifyError(fmt.Errorf("wrapped: %w", errSentinel)))
+	assertT.Equal("context deadline exceeded", config.classifyError(fmt.Errorf("call: %w", context.DeadlineExceeded)))
+	assertT.Equal("perform.codeError", config.classifyError(codeError{500}))
+	assertT.Equal("*errors.errorString", config.classifyError(errTest))
+
+	config = newRunConfig([]RunOption{WithErrorClassifier(ClassifyByMessage)})
+	assertT.Equal("code 404", config.classifyError(codeError{404}))
+	assertT.Equal("context canceled", config.classifyError(context.Canceled))
+}
+
+func TestAddFailure(t *testing.T) {
+	assertT := assert.New(t)
+
+	groups := make([]FailureGroup, 0)
+	for i := range 10 {
+		groups = addFailure(groups, "A", fmt.Sprintf("msg %d", i%5))
+	}
+	groups = addFailure(groups, "B", "msg")
+
+	assertT.Equal([]FailureGroup{
+		{Class: "A", Count: 10, Samples: []string{"msg 0", "msg 1", "msg 2"}},
+		{Class: "B", Count: 1, Samples: []string{"msg"}},
+	}, groups)
+}
+
+func TestRunTestFailures(t *testing.T) {
+	assertT := assert.New(t)
+
+	var callsCount atomic.Int32
+	task := func() error {
+		switch callsCount.Add(1) % 4 {
+		case 0:
+			return fmt.Errorf("wrapped: %w", errSentinel)
+		case 1:
+			return codeError{int(callsCount.Load())}
+		}
+		return nil
+	}
+
+	stats := RunTest([]TestTask{task}, TotalTests, Parallel, WithErrorClasses(errSentinel))
+
+	oneStat := stats[0]
+	assertT.Equal(TotalTests, oneStat.Count)
+	assertT.Equal(TotalTests/2, oneStat.Fails)
+	assertT.Equal(2, len(oneStat.Failures))
+	for _, group := range oneStat.Failures {
+		assertT.Equal(TotalTests/4, group.Count)
+		assertT.Contains([]string{"sentinel", "perform.codeError"}, group.Class)
+	}
+	assertT.Empty(oneStat.FailValues)
+}
+
+func TestSeparateFailures(t *testing.T) {
+	assertT := assert.New(t)
+
+	var callsCount atomic.Int32
+	task := func() error {
+		if callsCount.Add(1)%2 == 0 {
+			time.Sleep(SleepTime)
+			return errTest
+		}
+		return nil
+	}
+
+	stats := RunTest([]TestTask{task}, TotalTests, Parallel, WithSeparateFailures())
+
+	oneStat := stats[0]
+	assertT.Equal(TotalTests/2, oneStat.Count)
+	assertT.Equal(TotalTests/2, oneStat.Fails)
+	assertT.Equal(TotalTests/2, len(oneStat.FailValues))
+	assertT.Less(oneStat.MaxTime, float64(SleepTime)/msecFctr)
+	for _, v := range oneStat.FailValues {
+		assertT.GreaterOrEqual(v, float64(SleepTime)/msecFctr)
+	}
+}
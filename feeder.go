@@ -0,0 +1,202 @@
+package perform
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Supplies data records for runs; implementations should be safe for concurrent use
+type Feeder[R any] interface {
+	// Returns the next record; false when records are exhausted
+	Next() (R, bool)
+}
+
+// Order in which feeder supplies records
+type FeedStrategy int
+
+const (
+	// Records are supplied once in their original order; the test stops when they are exhausted
+	FeedSequential FeedStrategy = iota
+	// Records are supplied in their original order starting over when exhausted
+	FeedCircular
+	// Records are selected randomly
+	FeedRandom
+)
+
+// Task that receives a data record
+type FeedTask[R any] func(ctx context.Context, record R) error
+
+// Error returned by tasks when feeder has no more records. The run is not recorded and dispatching
+// of new runs stops.
+var ErrFeederExhausted = errors.New("feeder is exhausted")
+
+// Per-run data carried in context of tasks
+type runContext struct {
+	lock      sync.Mutex
+	tag       string
+	record    any // feeder record reused by retries
+	hasRecord bool
+}
+
+type runContextKey struct{}
+
+// Tags the current run - statistics of tagged runs are also collected separately in `RunStats.ByTag`
+func TagRun(ctx context.Context, tag string) {
+	if rc, ok := ctx.Value(runContextKey{}).(*runContext); ok {
+		rc.lock.Lock()
+		rc.tag = tag
+		rc.lock.Unlock()
+	}
+}
+
+func (rc *runContext) getTag() string {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.tag
+}
+
+// Converts task that consumes data records to `ContextTask`. Each run receives the next record from the feeder.
+// Optional "tagOf" function selects a tag of a record (see `TagRun`).
+func WithFeeder[R any](feeder Feeder[R], task FeedTask[R], tagOf func(R) string) ContextTask {
+	return func(ctx context.Context) error {
+		record, ok := nextRecord(ctx, feeder)
+		if !ok {
+			return ErrFeederExhausted
+		}
+		if tagOf != nil {
+			TagRun(ctx, tagOf(record))
+		}
+		return task(ctx, record)
+	}
+}
+
+// Returns the record of the current run when it is retried, otherwise the next record from the feeder
+func nextRecord[R any](ctx context.Context, feeder Feeder[R]) (R, bool) {
+	rc, ok := ctx.Value(runContextKey{}).(*runContext)
+	if !ok {
+		return feeder.Next()
+	}
+
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	if rc.hasRecord {
+		record, _ := rc.record.(R)
+		return record, true
+	}
+	record, ok := feeder.Next()
+	if ok {
+		rc.record = record
+		rc.hasRecord = true
+	}
+	return record, ok
+}
+
+// Tags CSV records by value of the field
+func TagByField(field string) func(map[string]string) string {
+	return func(record map[string]string) string { return record[field] }
+}
+
+type sliceFeeder[R any] struct {
+	lock     sync.Mutex
+	records  []R
+	strategy FeedStrategy
+	next     int
+	rng      *rand.Rand
+}
+
+// Creates feeder of in-memory records; "seed" is used by the random strategy
+func NewSliceFeeder[R any](records []R, strategy FeedStrategy, seed int64) Feeder[R] {
+	return &sliceFeeder[R]{records: records, strategy: strategy, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (f *sliceFeeder[R]) Next() (R, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var record R
+	if len(f.records) == 0 {
+		return record, false
+	}
+
+	switch f.strategy {
+	case FeedRandom:
+		record = f.records[f.rng.Intn(len(f.records))]
+	case FeedCircular:
+		record = f.records[f.next%len(f.records)]
+		f.next++
+	default:
+		if f.next >= len(f.records) {
+			return record, false
+		}
+		record = f.records[f.next]
+		f.next++
+	}
+	return record, true
+}
+
+// Creates feeder of CSV records. The first line should contain names of fields.
+// Records are represented as maps of field names to values.
+func NewCSVFeeder(r io.Reader, strategy FeedStrategy, seed int64) (Feeder[map[string]string], error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV header is missing")
+	}
+
+	header := rows[0]
+	records := make([]map[string]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]string, len(header))
+		for i, field := range header {
+			record[field] = row[i]
+		}
+		records = append(records, record)
+	}
+	return NewSliceFeeder(records, strategy, seed), nil
+}
+
+// Creates feeder of JSON lines records (one JSON value per line) decoded into type "R"
+func NewJSONLinesFeeder[R any](r io.Reader, strategy FeedStrategy, seed int64) (Feeder[R], error) {
+	records := make([]R, 0)
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var record R
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("invalid JSON record #%d: %v", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	return NewSliceFeeder(records, strategy, seed), nil
+}
+
+// Returns fixture collecting runs with the given tag; should be called under lock
+func (fixture *taskFixture) tagFixture(tag string) *taskFixture {
+	if fixture.byTag == nil {
+		fixture.byTag = make(map[string]*taskFixture)
+	}
+	tagged, ok := fixture.byTag[tag]
+	if !ok {
+		tagged = fixture.childFixture()
+		fixture.byTag[tag] = tagged
+	}
+	return tagged
+}
+
+// Creates fixture collecting a subset of runs with the same configuration and histogram layout
+func (fixture *taskFixture) childFixture() *taskFixture {
+	child := &taskFixture{config: fixture.config, runtimes: make([]time.Duration, 0), starts: make([]time.Time, 0)}
+	if fixture.hist != nil {
+		child.hist = NewHistogram(time.Duration(fixture.hist.lowest), time.Duration(fixture.hist.highest),
+			fixture.hist.significantDigits)
+	}
+	return child
+}
@@ -0,0 +1,121 @@
+package perform
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func takeRecords[R any](feeder Feeder[R], n int) []R {
+	ret := make([]R, 0, n)
+	for range n {
+		if r, ok := feeder.Next(); ok {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+func TestSliceFeeder(t *testing.T) {
+	assertT := assert.New(t)
+
+	records := []int{1, 2, 3}
+	assertT.Equal([]int{1, 2, 3}, takeRecords(NewSliceFeeder(records, FeedSequential, 0), 5))
+	assertT.Equal([]int{1, 2, 3, 1, 2}, takeRecords(NewSliceFeeder(records, FeedCircular, 0), 5))
+
+	random := takeRecords(NewSliceFeeder(records, FeedRandom, 7), 100)
+	assertT.Equal(100, len(random))
+	assertT.Equal(random, takeRecords(NewSliceFeeder(records, FeedRandom, 7), 100))
+	for _, r := range random {
+		assertT.Contains(records, r)
+	}
+
+	assertT.Empty(takeRecords(NewSliceFeeder([]int{}, FeedCircular, 0), 5))
+}
+
+func TestCSVFeeder(t *testing.T) {
+	assertT := assert.New(t)
+
+	feeder, err := NewCSVFeeder(strings.NewReader("method,path\nGET,/a\nPOST,/b\n"), FeedSequential, 0)
+	assertT.NoError(err)
+	records := takeRecords(feeder, 3)
+	assertT.Equal([]map[string]string{{"method": "GET", "path": "/a"}, {"method": "POST", "path": "/b"}}, records)
+	assertT.Equal("POST", TagByField("method")(records[1]))
+
+	_, err = NewCSVFeeder(strings.NewReader(""), FeedSequential, 0)
+	assertT.ErrorContains(err, "CSV header is missing")
+	_, err = NewCSVFeeder(strings.NewReader("a,b\n1\n"), FeedSequential, 0)
+	assertT.Error(err)
+}
+
+type jsonRecord struct {
+	ID    string `json:"request_id"`
+	Title string `json:"title"`
+}
+
+func TestJSONLinesFeeder(t *testing.T) {
+	assertT := assert.New(t)
+
+	data := `{"request_id": "r-1", "title": "first"}
+{"request_id": "r-2", "title": "second"}
+
+`
+	feeder, err := NewJSONLinesFeeder[jsonRecord](strings.NewReader(data), FeedSequential, 0)
+	assertT.NoError(err)
+	assertT.Equal([]jsonRecord{{"r-1", "first"}, {"r-2", "second"}}, takeRecords(feeder, 3))
+
+	_, err = NewJSONLinesFeeder[jsonRecord](strings.NewReader(data+"{oops}\n"), FeedSequential, 0)
+	assertT.ErrorContains(err, "invalid JSON record #3")
+}
+
+func TestRunTestWithFeeder(t *testing.T) {
+	assertT := assert.New(t)
+
+	records := make([]jsonRecord, 40)
+	for i := range records {
+		records[i] = jsonRecord{ID: "id", Title: []string{"read", "read", "read", "write"}[i%4]}
+	}
+
+	var lock sync.Mutex
+	seen := make(map[string]int)
+	task := func(_ context.Context, r jsonRecord) error {
+		lock.Lock()
+		seen[r.Title]++
+		lock.Unlock()
+		return nil
+	}
+	tagOf := func(r jsonRecord) string { return r.Title }
+
+	feedTask := WithFeeder(NewSliceFeeder(records, FeedSequential, 0), task, tagOf)
+	stats := RunTestContext(context.Background(), []ContextTask{feedTask}, TotalTests, Parallel)
+
+	oneStat := stats[0]
+	assertT.Equal(40, oneStat.Count)
+	assertT.Equal(0, oneStat.Fails)
+	assertT.True(oneStat.Incomplete)
+	assertT.Equal(map[string]int{"read": 30, "write": 10}, seen)
+	assertT.Equal(2, len(oneStat.ByTag))
+	assertT.Equal(30, oneStat.ByTag["read"].Count)
+	assertT.Equal(10, oneStat.ByTag["write"].Count)
+	assertT.Equal(len(DefaultPercentiles), len(oneStat.ByTag["write"].Percentiles))
+}
+
+func TestTagRunWithHistogram(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func(ctx context.Context) error {
+		TagRun(ctx, "tag")
+		return nil
+	}
+	stats := RunTestContext(context.Background(), []ContextTask{task}, TotalTests, Parallel,
+		WithHistogram(HistLowest, HistHighest, HistDigits))
+
+	assertT.Equal(TotalTests, stats[0].ByTag["tag"].Count)
+	assertT.NotNil(stats[0].ByTag["tag"].Histogram)
+	assertT.False(stats[0].Incomplete)
+
+	TagRun(context.Background(), "ignored")
+}
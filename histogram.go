@@ -0,0 +1,346 @@
+package perform
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Histogram of durations with bounded memory and configurable precision (HDR histogram).
+//
+// Values are counted in buckets whose width grows with magnitude of values, so that relative error of any
+// recorded value does not exceed 10^-significantDigits. Memory footprint depends only on the range and precision,
+// but not on number of recorded values.
+//
+// Based on https://github.com/HdrHistogram/hdrhistogram-go
+type Histogram struct {
+	lowest            int64
+	highest           int64
+	significantDigits int
+
+	unitMagnitude               int
+	subBucketHalfCountMagnitude int
+	subBucketCount              int
+	subBucketHalfCount          int
+	subBucketMask               int64
+
+	counts     []int64
+	totalCount int64
+	minValue   int64
+	maxValue   int64
+}
+
+// Serializable form of a histogram - range, precision, exact extremes and non-empty buckets
+type HistogramSnapshot struct {
+	Lowest            time.Duration     `json,yaml:"lowest"`
+	Highest           time.Duration     `json,yaml:"highest"`
+	SignificantDigits int               `json,yaml:"significant_digits"`
+	Min               time.Duration     `json,yaml:"min"`
+	Max               time.Duration     `json,yaml:"max"`
+	Buckets           []HistogramBucket `json,yaml:"buckets"`
+}
+
+// Non-empty bucket of a histogram
+type HistogramBucket struct {
+	Value time.Duration `json,yaml:"value"` // lowest equivalent value of the bucket
+	Count int64         `json,yaml:"count"`
+}
+
+var ErrHistogramMismatch = errors.New("histograms have different layout")
+var ErrInvalidSnapshot = errors.New("invalid histogram snapshot")
+
+// Records latencies in histograms instead of raw values. Values outside of [lowest, highest] range
+// are clamped to its bounds; "significantDigits" (1..5) define precision of recorded values.
+// `RunStats.Values` and `RunStats.Starts` are not collected in this mode. Warm-up times (`RunStats.Warmup`) and times
+// of failed runs (`RunStats.FailValues`) are still kept as raw values - their number is expected to be small;
+// first attempts of retried runs are recorded in histograms as well.
+func WithHistogram(lowest, highest time.Duration, significantDigits int) RunOption {
+	return func(c *runConfig) {
+		c.histogram = &histogramConfig{lowest: lowest, highest: highest, significantDigits: significantDigits}
+	}
+}
+
+type histogramConfig struct {
+	lowest            time.Duration
+	highest           time.Duration
+	significantDigits int
+}
+
+// Creates histogram for durations in the range [lowest, highest] (lowest >= 1ns)
+// with precision of "significantDigits" decimal digits (1..5).
+func NewHistogram(lowest, highest time.Duration, significantDigits int) *Histogram {
+	if lowest < 1 || highest < 2*lowest {
+		panic("invalid histogram range")
+	}
+	if significantDigits < 1 || significantDigits > 5 {
+		panic("histogram precision should be in range 1..5")
+	}
+
+	h := &Histogram{lowest: int64(lowest), highest: int64(highest), significantDigits: significantDigits}
+
+	largestSingleUnitValue := 2 * int64(math.Pow10(significantDigits))
+	subBucketCountMagnitude := int(math.Ceil(math.Log2(float64(largestSingleUnitValue))))
+	h.subBucketHalfCountMagnitude = max(subBucketCountMagnitude, 1) - 1
+	h.unitMagnitude = int(math.Floor(math.Log2(float64(lowest))))
+	h.subBucketCount = 1 << (h.subBucketHalfCountMagnitude + 1)
+	h.subBucketHalfCount = h.subBucketCount / 2
+	h.subBucketMask = int64(h.subBucketCount-1) << h.unitMagnitude
+
+	smallestUntrackable := int64(h.subBucketCount) << h.unitMagnitude
+	bucketCount := 1
+	for smallestUntrackable < h.highest {
+		smallestUntrackable <<= 1
+		bucketCount++
+	}
+
+	h.counts = make([]int64, (bucketCount+1)*h.subBucketHalfCount)
+	h.minValue = math.MaxInt64
+	return h
+}
+
+// Restores histogram from its snapshot
+func NewHistogramFromSnapshot(snapshot HistogramSnapshot) (*Histogram, error) {
+	if snapshot.Lowest < 1 || snapshot.Highest < 2*snapshot.Lowest ||
+		snapshot.SignificantDigits < 1 || snapshot.SignificantDigits > 5 {
+		return nil, ErrInvalidSnapshot
+	}
+
+	h := NewHistogram(snapshot.Lowest, snapshot.Highest, snapshot.SignificantDigits)
+	for _, bucket := range snapshot.Buckets {
+		if bucket.Value < 0 || bucket.Value > snapshot.Highest || bucket.Count < 0 {
+			return nil, ErrInvalidSnapshot
+		}
+		h.counts[h.countsIndexFor(int64(bucket.Value))] += bucket.Count
+		h.totalCount += bucket.Count
+	}
+	if h.totalCount > 0 {
+		h.minValue = int64(snapshot.Min)
+		h.maxValue = int64(snapshot.Max)
+	}
+	return h, nil
+}
+
+// Creates serializable snapshot of the histogram
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	snapshot := HistogramSnapshot{Lowest: time.Duration(h.lowest), Highest: time.Duration(h.highest),
+		SignificantDigits: h.significantDigits, Min: h.Min(), Max: h.Max(), Buckets: make([]HistogramBucket, 0)}
+	h.forEachBucket(func(value, count int64) {
+		snapshot.Buckets = append(snapshot.Buckets, HistogramBucket{Value: time.Duration(value), Count: count})
+	})
+	return snapshot
+}
+
+// Serializes the histogram as its snapshot
+func (h *Histogram) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Snapshot())
+}
+
+// Restores the histogram from serialized snapshot
+func (h *Histogram) UnmarshalJSON(data []byte) error {
+	var snapshot HistogramSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	return h.restore(snapshot)
+}
+
+// Serializes the histogram to YAML as its snapshot
+func (h *Histogram) MarshalYAML() (any, error) {
+	return h.Snapshot(), nil
+}
+
+// Restores the histogram from YAML snapshot
+func (h *Histogram) UnmarshalYAML(unmarshal func(any) error) error {
+	var snapshot HistogramSnapshot
+	if err := unmarshal(&snapshot); err != nil {
+		return err
+	}
+	return h.restore(snapshot)
+}
+
+func (h *Histogram) restore(snapshot HistogramSnapshot) error {
+	restored, err := NewHistogramFromSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	*h = *restored
+	return nil
+}
+
+// Records one duration
+func (h *Histogram) Record(d time.Duration) {
+	h.recordCount(min(max(int64(d), h.lowest), h.highest), 1)
+}
+
+func (h *Histogram) recordCount(v int64, n int64) {
+	h.counts[h.countsIndexFor(v)] += n
+	h.totalCount += n
+	h.minValue = min(h.minValue, v)
+	h.maxValue = max(h.maxValue, v)
+}
+
+// Adds all values from another histogram with the same range and precision
+func (h *Histogram) Merge(other *Histogram) error {
+	if h.lowest != other.lowest || h.highest != other.highest || h.significantDigits != other.significantDigits {
+		return ErrHistogramMismatch
+	}
+
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.totalCount += other.totalCount
+	h.minValue = min(h.minValue, other.minValue)
+	h.maxValue = max(h.maxValue, other.maxValue)
+	return nil
+}
+
+// Number of recorded values
+func (h *Histogram) Count() int {
+	return int(h.totalCount)
+}
+
+// Smallest recorded value (exact)
+func (h *Histogram) Min() time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	return time.Duration(h.minValue)
+}
+
+// Largest recorded value (exact)
+func (h *Histogram) Max() time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+	return time.Duration(h.maxValue)
+}
+
+// Mean of recorded values
+func (h *Histogram) Mean() time.Duration {
+	return time.Duration(h.mean())
+}
+
+// Sample standard deviation of recorded values
+func (h *Histogram) StdDev() time.Duration {
+	if h.totalCount <= 1 {
+		return 0
+	}
+
+	m := h.mean()
+	sumSquares := 0.0
+	h.forEachBucket(func(value, count int64) {
+		dev := float64(h.medianEquivalentValue(value)) - m
+		sumSquares += dev * dev * float64(count)
+	})
+	return time.Duration(math.Sqrt(sumSquares / float64(h.totalCount-1)))
+}
+
+// Value at the given percentile level (0..100)
+func (h *Histogram) ValueAtPercentile(level float64) time.Duration {
+	if h.totalCount == 0 {
+		return 0
+	}
+
+	countAtLevel := max(int64(min(level, 100)/100*float64(h.totalCount)+0.5), 1)
+	total := int64(0)
+	ret := int64(0)
+	h.forEachBucket(func(value, count int64) {
+		if total < countAtLevel {
+			total += count
+			if total >= countAtLevel {
+				ret = h.highestEquivalentValue(value)
+			}
+		}
+	})
+	return time.Duration(min(ret, h.maxValue))
+}
+
+// Creates statistics with values calculated from the histogram
+func (h *Histogram) Stats(levels []float64) RunStats {
+	var stats RunStats
+	stats.Count = h.Count()
+	if stats.Count == 0 {
+		return stats
+	}
+
+	stats.AvgTime = h.mean() / msecFctr
+	stats.MinTime = float64(h.Min()) / msecFctr
+	stats.MaxTime = float64(h.Max()) / msecFctr
+	stats.MedTime = float64(h.ValueAtPercentile(50)) / msecFctr
+	stats.StdDev = float64(h.StdDev()) / msecFctr
+	stats.Percentiles = make([]Percentile, len(levels))
+	for i, level := range levels {
+		stats.Percentiles[i] = Percentile{Level: level, Value: float64(h.ValueAtPercentile(level)) / msecFctr}
+	}
+	return stats
+}
+
+func (h *Histogram) mean() float64 {
+	if h.totalCount == 0 {
+		return 0
+	}
+
+	total := 0.0
+	h.forEachBucket(func(value, count int64) {
+		total += float64(h.medianEquivalentValue(value)) * float64(count)
+	})
+	return total / float64(h.totalCount)
+}
+
+// Calls "f" for non-empty buckets in ascending order of values
+func (h *Histogram) forEachBucket(f func(value, count int64)) {
+	for i, count := range h.counts {
+		if count != 0 {
+			f(h.valueFromIndex(i), count)
+		}
+	}
+}
+
+func (h *Histogram) bucketIndex(v int64) int {
+	pow2Ceiling := bits.Len64(uint64(v | h.subBucketMask))
+	return pow2Ceiling - h.unitMagnitude - (h.subBucketHalfCountMagnitude + 1)
+}
+
+func (h *Histogram) subBucketIndex(v int64, bucketIdx int) int {
+	return int(v >> (bucketIdx + h.unitMagnitude))
+}
+
+func (h *Histogram) countsIndexFor(v int64) int {
+	bucketIdx := h.bucketIndex(v)
+	subBucketIdx := h.subBucketIndex(v, bucketIdx)
+	return (bucketIdx+1)<<h.subBucketHalfCountMagnitude + subBucketIdx - h.subBucketHalfCount
+}
+
+func (h *Histogram) valueFromIndex(idx int) int64 {
+	bucketIdx := idx>>h.subBucketHalfCountMagnitude - 1
+	subBucketIdx := idx&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucketIdx < 0 {
+		subBucketIdx -= h.subBucketHalfCount
+		bucketIdx = 0
+	}
+	return int64(subBucketIdx) << (bucketIdx + h.unitMagnitude)
+}
+
+func (h *Histogram) equivalentRange(v int64) int64 {
+	bucketIdx := h.bucketIndex(v)
+	if h.subBucketIndex(v, bucketIdx) >= h.subBucketCount {
+		bucketIdx++
+	}
+	return 1 << (h.unitMagnitude + bucketIdx)
+}
+
+func (h *Histogram) lowestEquivalentValue(v int64) int64 {
+	bucketIdx := h.bucketIndex(v)
+	subBucketIdx := h.subBucketIndex(v, bucketIdx)
+	return int64(subBucketIdx) << (bucketIdx + h.unitMagnitude)
+}
+
+func (h *Histogram) highestEquivalentValue(v int64) int64 {
+	return h.lowestEquivalentValue(v) + h.equivalentRange(v) - 1
+}
+
+func (h *Histogram) medianEquivalentValue(v int64) int64 {
+	return h.lowestEquivalentValue(v) + h.equivalentRange(v)>>1
+}
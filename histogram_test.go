@@ -0,0 +1,168 @@
+package perform
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	HistLowest  = time.Microsecond
+	HistHighest = time.Minute
+	HistDigits  = 3
+)
+
+func assertRelClose(t *testing.T, expected, actual float64, relErr float64) {
+	t.Helper()
+	assert.InDelta(t, expected, actual, math.Abs(expected)*relErr)
+}
+
+func TestHistogramRecord(t *testing.T) {
+	assertT := assert.New(t)
+
+	h := NewHistogram(HistLowest, HistHighest, HistDigits)
+	values := make([]float64, 0, 10000)
+	for i := 1; i <= 10000; i++ {
+		h.Record(time.Duration(i) * time.Microsecond)
+		values = append(values, float64(i))
+	}
+
+	assertT.Equal(10000, h.Count())
+	assertT.Equal(time.Microsecond, h.Min())
+	assertT.Equal(10000*time.Microsecond, h.Max())
+	assertT.Equal(h.Max(), h.ValueAtPercentile(100))
+	assertRelClose(t, mean(values), float64(h.Mean())/1000, 1e-3)
+	assertRelClose(t, math.Sqrt(variance(values)), float64(h.StdDev())/1000, 1e-3)
+	assertRelClose(t, 5000, float64(h.ValueAtPercentile(50))/1000, 1e-3)
+	assertRelClose(t, 9900, float64(h.ValueAtPercentile(99))/1000, 1e-3)
+	assertRelClose(t, 9990, float64(h.ValueAtPercentile(99.9))/1000, 1e-3)
+}
+
+func TestHistogramClamp(t *testing.T) {
+	assertT := assert.New(t)
+
+	h := NewHistogram(HistLowest, time.Second, HistDigits)
+	h.Record(0)
+	h.Record(time.Hour)
+
+	assertT.Equal(2, h.Count())
+	assertT.Equal(HistLowest, h.Min())
+	assertT.Equal(time.Second, h.Max())
+}
+
+func TestHistogramMerge(t *testing.T) {
+	assertT := assert.New(t)
+
+	h1 := NewHistogram(HistLowest, HistHighest, HistDigits)
+	h2 := NewHistogram(HistLowest, HistHighest, HistDigits)
+	hAll := NewHistogram(HistLowest, HistHighest, HistDigits)
+	for i := 1; i <= 1000; i++ {
+		d := time.Duration(i*i) * time.Microsecond
+		if i%3 == 0 {
+			h1.Record(d)
+		} else {
+			h2.Record(d)
+		}
+		hAll.Record(d)
+	}
+
+	assertT.NoError(h1.Merge(h2))
+	assertT.Equal(hAll.Count(), h1.Count())
+	assertT.Equal(hAll.counts, h1.counts)
+	assertT.Equal(hAll.Min(), h1.Min())
+	assertT.Equal(hAll.Max(), h1.Max())
+	assertT.Equal(hAll.ValueAtPercentile(99), h1.ValueAtPercentile(99))
+
+	assertT.ErrorIs(h1.Merge(NewHistogram(HistLowest, HistHighest, 2)), ErrHistogramMismatch)
+}
+
+func TestHistogramSnapshot(t *testing.T) {
+	assertT := assert.New(t)
+
+	h := NewHistogram(HistLowest, HistHighest, HistDigits)
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i*i) * time.Microsecond)
+	}
+
+	data, err := json.Marshal(RunStats{Count: h.Count(), Histogram: h})
+	assertT.NoError(err)
+	var restored RunStats
+	assertT.NoError(json.Unmarshal(data, &restored))
+
+	assertT.NotNil(restored.Histogram)
+	assertT.Equal(h.counts, restored.Histogram.counts)
+	assertT.Equal(h.Count(), restored.Histogram.Count())
+	assertT.Equal(h.Min(), restored.Histogram.Min())
+	assertT.Equal(h.Max(), restored.Histogram.Max())
+	assertT.Equal(h.ValueAtPercentile(99), restored.Histogram.ValueAtPercentile(99))
+	assertT.NoError(restored.Histogram.Merge(h))
+	assertT.Equal(2*h.Count(), restored.Histogram.Count())
+
+	empty, err := NewHistogramFromSnapshot(NewHistogram(HistLowest, HistHighest, HistDigits).Snapshot())
+	assertT.NoError(err)
+	assertT.Equal(0, empty.Count())
+	assertT.NoError(empty.Merge(h))
+	assertT.Equal(h.Min(), empty.Min())
+
+	yamlForm, err := h.MarshalYAML()
+	assertT.NoError(err)
+	var fromYaml Histogram
+	assertT.NoError(fromYaml.UnmarshalYAML(func(out any) error {
+		*out.(*HistogramSnapshot) = yamlForm.(HistogramSnapshot)
+		return nil
+	}))
+	assertT.Equal(h.counts, fromYaml.counts)
+	assertT.Equal(h.Max(), fromYaml.Max())
+
+	snapshot := h.Snapshot()
+	snapshot.Buckets[0].Value = HistHighest + 1
+	_, err = NewHistogramFromSnapshot(snapshot)
+	assertT.ErrorIs(err, ErrInvalidSnapshot)
+	_, err = NewHistogramFromSnapshot(HistogramSnapshot{Lowest: HistLowest, Highest: HistHighest})
+	assertT.ErrorIs(err, ErrInvalidSnapshot)
+}
+
+func TestHistogramEmpty(t *testing.T) {
+	assertT := assert.New(t)
+
+	h := NewHistogram(HistLowest, HistHighest, HistDigits)
+	assertT.Equal(time.Duration(0), h.Min())
+	assertT.Equal(time.Duration(0), h.Max())
+	assertT.Equal(time.Duration(0), h.Mean())
+	assertT.Equal(time.Duration(0), h.StdDev())
+	assertT.Equal(time.Duration(0), h.ValueAtPercentile(50))
+	assertT.Equal(0, h.Stats(DefaultPercentiles).Count)
+}
+
+func TestHistogramFailures(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Panics(func() { NewHistogram(0, time.Second, HistDigits) })
+	assertT.Panics(func() { NewHistogram(time.Second, time.Second, HistDigits) })
+	assertT.Panics(func() { NewHistogram(HistLowest, HistHighest, 0) })
+	assertT.Panics(func() { NewHistogram(HistLowest, HistHighest, 6) })
+}
+
+func TestRunTestWithHistogram(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func() error {
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	stats := RunTest([]TestTask{task}, TotalTests, Parallel, WithHistogram(HistLowest, HistHighest, HistDigits),
+		WithWarmupRuns(WarmupRuns))
+
+	oneStat := stats[0]
+	assertT.Equal(TotalTests, oneStat.Count)
+	assertT.Empty(oneStat.Values)
+	assertT.Equal(WarmupRuns, len(oneStat.Warmup))
+	assertT.NotNil(oneStat.Histogram)
+	assertT.GreaterOrEqual(oneStat.MinTime, 0.999)
+	assertT.GreaterOrEqual(oneStat.MedTime, oneStat.MinTime)
+	assertT.GreaterOrEqual(oneStat.MaxTime, oneStat.MedTime)
+	assertT.Equal(len(DefaultPercentiles), len(oneStat.Percentiles))
+}
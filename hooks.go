@@ -0,0 +1,197 @@
+package perform
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Lifecycle hooks of a test; all hooks are optional. Time spent in hooks is excluded from measured latencies.
+//
+// "S" is the type of worker-local state (e.g. HTTP client or DB session) created by `SetupWorker`.
+// The state is passed to the run hooks and can be obtained by tasks with `WorkerState` (see also `WithState`).
+// The state is never used by two runs at once - when a run is abandoned after `WithRunTimeout`, the worker
+// sets up a new state (excluded from latencies unless the run is retried), and the old one is torn down
+// only when the abandoned task returns (possibly after `TeardownSuite`).
+type Hooks[S any] struct {
+	// Called once before the test; on error the test is not started
+	SetupSuite func(ctx context.Context) error
+	// Called once after the test and teardown of all workers
+	TeardownSuite func(ctx context.Context)
+	// Called when a worker starts; on error all runs executed by the worker fail with this error
+	SetupWorker func(ctx context.Context, worker int) (S, error)
+	// Called when a worker stops
+	TeardownWorker func(ctx context.Context, state S)
+	// Called before each run; on error the task is not called and the run fails
+	BeforeRun func(ctx context.Context, state S) error
+	// Called after each run with the run result
+	AfterRun func(ctx context.Context, state S, err error)
+}
+
+// Task that receives worker-local state
+type StatefulTask[S any] func(ctx context.Context, state S) error
+
+// Type-erased hooks used by the runner
+type runHooks struct {
+	setupSuite     func(ctx context.Context) error
+	teardownSuite  func(ctx context.Context)
+	setupWorker    func(ctx context.Context, worker int) (any, error)
+	teardownWorker func(ctx context.Context, state any)
+	beforeRun      func(ctx context.Context, state any) error
+	afterRun       func(ctx context.Context, state any, err error)
+}
+
+// Worker-local data carried in context of tasks
+type workerContext struct {
+	id      int
+	state   any
+	err     error      // worker setup error
+	rng     *rand.Rand // think time generator
+	retired bool       // state was handed over to an abandoned task
+}
+
+type workerContextKey struct{}
+
+// Error class of failed suite setup
+const suiteSetupClass = "suite setup"
+
+// Sets lifecycle hooks of the test
+func WithHooks[S any](hooks Hooks[S]) RunOption {
+	h := runHooks{setupSuite: hooks.SetupSuite, teardownSuite: hooks.TeardownSuite}
+	if hooks.SetupWorker != nil {
+		h.setupWorker = func(ctx context.Context, worker int) (any, error) { return hooks.SetupWorker(ctx, worker) }
+	}
+	if hooks.TeardownWorker != nil {
+		h.teardownWorker = func(ctx context.Context, state any) { hooks.TeardownWorker(ctx, castState[S](state)) }
+	}
+	if hooks.BeforeRun != nil {
+		h.beforeRun = func(ctx context.Context, state any) error { return hooks.BeforeRun(ctx, castState[S](state)) }
+	}
+	if hooks.AfterRun != nil {
+		h.afterRun = func(ctx context.Context, state any, err error) { hooks.AfterRun(ctx, castState[S](state), err) }
+	}
+
+	return func(c *runConfig) { c.hooks = h }
+}
+
+// Returns worker-local state created by `Hooks.SetupWorker` (zero value if there is none)
+func WorkerState[S any](ctx context.Context) S {
+	if wc, ok := ctx.Value(workerContextKey{}).(*workerContext); ok {
+		return castState[S](wc.state)
+	}
+	var zero S
+	return zero
+}
+
+// Converts stateful task to `ContextTask` that obtains the state from its context
+func WithState[S any](task StatefulTask[S]) ContextTask {
+	return func(ctx context.Context) error { return task(ctx, WorkerState[S](ctx)) }
+}
+
+func castState[S any](state any) S {
+	s, _ := state.(S)
+	return s
+}
+
+// Calls suite setup hook
+func (run *testRun) setupSuite(ctx context.Context) error {
+	if run.config.hooks.setupSuite == nil {
+		return nil
+	}
+	if err := run.config.hooks.setupSuite(ctx); err != nil {
+		return err
+	}
+	run.suiteReady = true
+	return nil
+}
+
+// Fills the report, stops progress reporting, terminates workers and tears down the suite
+func (run *testRun) finish(ctx context.Context) {
+	run.fillReport()
+	run.tracker.stop()
+	close(run.queue)
+	run.workerGroup.Wait()
+	if run.suiteReady && run.config.hooks.teardownSuite != nil {
+		run.config.hooks.teardownSuite(context.WithoutCancel(ctx))
+	}
+}
+
+// Marks statistics of the test that was not started because of suite setup failure
+func markSetupFailure(stats []RunStats, err error) {
+	markIncomplete(stats)
+	for i := range stats {
+		stats[i].Failures = addFailure(stats[i].Failures, suiteSetupClass, err.Error())
+	}
+}
+
+// Creates worker-local context with the state from worker setup hook
+func (run *testRun) setupWorker(ctx context.Context, worker int) context.Context {
+	wc := &workerContext{id: worker, rng: rand.New(rand.NewSource(run.config.thinkSeed + int64(worker)))}
+	run.config.setupState(ctx, wc)
+	return context.WithValue(ctx, workerContextKey{}, wc)
+}
+
+func (run *testRun) teardownWorker(ctx context.Context) {
+	run.config.teardownState(ctx, *ctx.Value(workerContextKey{}).(*workerContext))
+}
+
+func (c *runConfig) setupState(ctx context.Context, wc *workerContext) {
+	wc.state, wc.err, wc.retired = nil, nil, false
+	if c.hooks.setupWorker != nil {
+		wc.state, wc.err = c.hooks.setupWorker(ctx, wc.id)
+		if wc.err != nil {
+			wc.err = fmt.Errorf("worker setup: %w", wc.err)
+		}
+	}
+}
+
+func (c *runConfig) teardownState(ctx context.Context, wc workerContext) {
+	if wc.err == nil && !wc.retired && c.hooks.teardownWorker != nil {
+		c.hooks.teardownWorker(context.WithoutCancel(ctx), wc.state)
+	}
+}
+
+// Hands the worker state over to the task abandoned after timeout - the state is torn down when the task returns.
+// The worker gets a new state before its next run.
+func (c *runConfig) retireState(ctx context.Context, wc *workerContext, done <-chan error) {
+	abandoned := *wc
+	go func() {
+		<-done
+		c.teardownState(ctx, abandoned)
+	}()
+	wc.state, wc.retired = nil, true
+}
+
+// Sets up a new state if the previous one was retired
+func (c *runConfig) renewState(ctx context.Context) {
+	if wc, ok := ctx.Value(workerContextKey{}).(*workerContext); ok && wc.retired {
+		c.setupState(ctx, wc)
+	}
+}
+
+// Calls hook before the run; returns time spent in the hook and error that prevents the run
+func (fixture *taskFixture) beforeRun(ctx context.Context) (time.Duration, error) {
+	wc, ok := ctx.Value(workerContextKey{}).(*workerContext)
+	if !ok {
+		return 0, nil
+	}
+	if wc.err != nil {
+		return 0, wc.err
+	}
+	if fixture.config.hooks.beforeRun == nil {
+		return 0, nil
+	}
+
+	start := time.Now()
+	err := fixture.config.hooks.beforeRun(ctx, wc.state)
+	return time.Since(start), err
+}
+
+// Renews retired worker state and calls hook after the run
+func (fixture *taskFixture) afterRun(ctx context.Context, err error) {
+	fixture.config.renewState(ctx)
+	if wc, ok := ctx.Value(workerContextKey{}).(*workerContext); ok && fixture.config.hooks.afterRun != nil {
+		fixture.config.hooks.afterRun(ctx, wc.state, err)
+	}
+}
@@ -0,0 +1,226 @@
+package perform
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type workerState struct {
+	id   int
+	runs int
+}
+
+type hookCounters struct {
+	lock           sync.Mutex
+	setupSuite     int
+	teardownSuite  int
+	setupWorker    int
+	teardownWorker int
+	beforeRun      int
+	afterRun       int
+	workerRuns     int
+	order          []string
+}
+
+func (hc *hookCounters) inc(counter *int, name string) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	*counter++
+	if name != "" {
+		hc.order = append(hc.order, name)
+	}
+}
+
+func (hc *hookCounters) hooks() Hooks[*workerState] {
+	return Hooks[*workerState]{
+		SetupSuite: func(context.Context) error {
+			hc.inc(&hc.setupSuite, "setupSuite")
+			return nil
+		},
+		TeardownSuite: func(context.Context) { hc.inc(&hc.teardownSuite, "teardownSuite") },
+		SetupWorker: func(_ context.Context, worker int) (*workerState, error) {
+			hc.inc(&hc.setupWorker, "")
+			return &workerState{id: worker}, nil
+		},
+		TeardownWorker: func(_ context.Context, state *workerState) {
+			hc.inc(&hc.teardownWorker, "teardownWorker")
+			hc.lock.Lock()
+			hc.workerRuns += state.runs
+			hc.lock.Unlock()
+		},
+		BeforeRun: func(context.Context, *workerState) error {
+			time.Sleep(SleepTime)
+			hc.inc(&hc.beforeRun, "")
+			return nil
+		},
+		AfterRun: func(context.Context, *workerState, error) {
+			time.Sleep(SleepTime)
+			hc.inc(&hc.afterRun, "")
+		},
+	}
+}
+
+func TestHooksLifecycle(t *testing.T) {
+	assertT := assert.New(t)
+
+	var counters hookCounters
+	task := WithState(func(_ context.Context, state *workerState) error {
+		state.runs++
+		return nil
+	})
+
+	stats := RunTestContext(context.Background(), []ContextTask{task}, TotalTests, Parallel, WithHooks(counters.hooks()))
+
+	oneStat := stats[0]
+	assertT.Equal(TotalTests, oneStat.Count)
+	assertT.Equal(0, oneStat.Fails)
+	assertT.Less(oneStat.MaxTime, float64(SleepTime)/msecFctr)
+
+	assertT.Equal(1, counters.setupSuite)
+	assertT.Equal(1, counters.teardownSuite)
+	assertT.LessOrEqual(counters.setupWorker, Parallel)
+	assertT.Equal(counters.setupWorker, counters.teardownWorker)
+	assertT.Equal(TotalTests, counters.beforeRun)
+	assertT.Equal(TotalTests, counters.afterRun)
+	assertT.Equal(TotalTests, counters.workerRuns)
+	assertT.Equal("setupSuite", counters.order[0])
+	assertT.Equal("teardownSuite", counters.order[len(counters.order)-1])
+}
+
+func TestSetupSuiteFailure(t *testing.T) {
+	assertT := assert.New(t)
+
+	var callsCount atomic.Int32
+	task := func(context.Context) error {
+		callsCount.Add(1)
+		return nil
+	}
+	hooks := Hooks[any]{
+		SetupSuite:    func(context.Context) error { return errTest },
+		TeardownSuite: func(context.Context) { callsCount.Add(100) },
+	}
+
+	stats := RunTestContext(context.Background(), []ContextTask{task}, TotalTests, Parallel, WithHooks(hooks))
+
+	assertT.Equal(0, int(callsCount.Load()))
+	assertT.Equal(0, stats[0].Count)
+	assertT.True(stats[0].Incomplete)
+	assertT.Equal([]FailureGroup{{Class: "suite setup", Count: 1, Samples: []string{"test error"}}}, stats[0].Failures)
+
+	stages := RunTestProfile(context.Background(), []ContextTask{task}, LinearRamp(1, 2, SleepTime), WithHooks(hooks))
+	assertT.Equal(0, int(callsCount.Load()))
+	assertT.True(stages[1].Stats[0].Incomplete)
+	assertT.Equal("suite setup", stages[1].Stats[0].Failures[0].Class)
+}
+
+func TestSetupWorkerFailure(t *testing.T) {
+	assertT := assert.New(t)
+
+	var callsCount atomic.Int32
+	task := func(context.Context) error {
+		callsCount.Add(1)
+		return nil
+	}
+	hooks := Hooks[int]{
+		SetupWorker:    func(context.Context, int) (int, error) { return 0, errTest },
+		TeardownWorker: func(context.Context, int) { callsCount.Add(100) },
+	}
+
+	stats := RunTestContext(context.Background(), []ContextTask{task}, TotalTests, Parallel, WithHooks(hooks))
+
+	assertT.Equal(0, int(callsCount.Load()))
+	assertT.Equal(TotalTests, stats[0].Fails)
+	assertT.Equal(1, len(stats[0].Failures))
+	assertT.Equal([]string{"worker setup: test error"}, stats[0].Failures[0].Samples)
+	assertT.False(stats[0].Incomplete)
+}
+
+func TestBeforeRunFailure(t *testing.T) {
+	assertT := assert.New(t)
+
+	var callsCount atomic.Int32
+	var beforeCount atomic.Int32
+	var afterErrors atomic.Int32
+	errSkip := errors.New("skip")
+	task := func(context.Context) error {
+		callsCount.Add(1)
+		return nil
+	}
+	hooks := Hooks[int]{
+		BeforeRun: func(context.Context, int) error {
+			if beforeCount.Add(1)%2 == 0 {
+				return errSkip
+			}
+			return nil
+		},
+		AfterRun: func(_ context.Context, _ int, err error) {
+			if err != nil {
+				afterErrors.Add(1)
+			}
+		},
+	}
+
+	stats := RunTestContext(context.Background(), []ContextTask{task}, TotalTests, 1, WithHooks(hooks))
+
+	assertT.Equal(TotalTests, stats[0].Count)
+	assertT.Equal(TotalTests/2, int(callsCount.Load()))
+	assertT.Equal(TotalTests/2, stats[0].Fails)
+	assertT.Equal(stats[0].Fails, int(afterErrors.Load()))
+}
+
+func TestWorkerStateWithoutHooks(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Nil(WorkerState[*workerState](context.Background()))
+	assertT.Equal(0, WorkerState[int](context.Background()))
+}
+
+func TestWorkerStateWithRunTimeout(t *testing.T) {
+	assertT := assert.New(t)
+
+	type session struct{ users atomic.Int32 }
+	var setups, teardowns, shared atomic.Int32
+	var abandoned sync.WaitGroup
+	hooks := Hooks[*session]{
+		SetupWorker: func(context.Context, int) (*session, error) {
+			setups.Add(1)
+			return &session{}, nil
+		},
+		TeardownWorker: func(_ context.Context, s *session) {
+			if s.users.Load() != 0 {
+				shared.Add(1)
+			}
+			teardowns.Add(1)
+		},
+	}
+	calls := atomic.Int32{}
+	task := func(ctx context.Context, s *session) error {
+		if s.users.Add(1) > 1 {
+			shared.Add(1)
+		}
+		defer s.users.Add(-1)
+		if calls.Add(1)%3 == 0 {
+			// ignores cancellation
+			abandoned.Add(1)
+			defer abandoned.Done()
+			time.Sleep(3 * SleepTime)
+		}
+		return nil
+	}
+
+	stats := RunTestContext(context.Background(), []ContextTask{WithState(task)}, 30, 2,
+		WithRunTimeout(SleepTime), WithHooks(hooks))
+	abandoned.Wait()
+
+	assertT.Equal(10, stats[0].Timeouts)
+	assertT.Equal(int32(2+10), setups.Load())
+	// state of abandoned tasks is torn down when they return
+	assertT.Eventually(func() bool { return teardowns.Load() == setups.Load() }, time.Second, time.Millisecond)
+	assertT.Equal(int32(0), shared.Load())
+}
@@ -0,0 +1,82 @@
+package perform
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Selects index of the task for the next run
+type taskSelector func() int
+
+// Sets relative shares of tasks in the test. Tasks are interleaved deterministically,
+// e.g. weights 4 and 1 produce sequence "0 0 1 0 0 0 0 1 0 0 ...". The number of weights should match
+// the number of tasks.
+func WithWeights(weights ...int) RunOption {
+	return func(c *runConfig) { c.weights = weights }
+}
+
+// Selects tasks randomly (according to weights, if set) with the given seed, so that the mix is reproducible
+func WithRandomMix(seed int64) RunOption {
+	return func(c *runConfig) {
+		c.randomMix = true
+		c.mixSeed = seed
+	}
+}
+
+func newTaskSelector(config runConfig, numTasks int) taskSelector {
+	weights := config.weights
+	if weights == nil {
+		weights = make([]int, numTasks)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != numTasks {
+		panic("number of weights should match number of tasks")
+	}
+	total := 0
+	for _, w := range weights {
+		if w < 0 {
+			panic("task weights should not be negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		panic("at least one task weight should be positive")
+	}
+
+	if config.randomMix {
+		return randomSelector(weights, total, config.mixSeed)
+	}
+	return interleavedSelector(weights, total)
+}
+
+// Smooth weighted round-robin (as in Nginx) - spreads runs of each task evenly
+func interleavedSelector(weights []int, total int) taskSelector {
+	current := make([]int, len(weights))
+	return func() int {
+		best := 0
+		for i, w := range weights {
+			current[i] += w
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		return best
+	}
+}
+
+func randomSelector(weights []int, total int, seed int64) taskSelector {
+	rng := rand.New(rand.NewSource(seed))
+	cumulative := make([]int, len(weights))
+	sum := 0
+	for i, w := range weights {
+		sum += w
+		cumulative[i] = sum
+	}
+	return func() int {
+		r := rng.Intn(total)
+		return sort.SearchInts(cumulative, r+1)
+	}
+}
@@ -0,0 +1,65 @@
+package perform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func takeSelections(selector taskSelector, n int) []int {
+	ret := make([]int, n)
+	for i := range ret {
+		ret[i] = selector()
+	}
+	return ret
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	assertT := assert.New(t)
+
+	selector := newTaskSelector(runConfig{}, 3)
+	assertT.Equal([]int{0, 1, 2, 0, 1, 2}, takeSelections(selector, 6))
+}
+
+func TestInterleavedSelector(t *testing.T) {
+	assertT := assert.New(t)
+
+	selector := newTaskSelector(newRunConfig([]RunOption{WithWeights(4, 1)}), 2)
+	assertT.Equal([]int{0, 0, 1, 0, 0, 0, 0, 1, 0, 0}, takeSelections(selector, 10))
+
+	selector = newTaskSelector(newRunConfig([]RunOption{WithWeights(0, 2, 1)}), 3)
+	assertT.Equal([]int{1, 2, 1, 1, 2, 1}, takeSelections(selector, 6))
+}
+
+func TestRandomSelector(t *testing.T) {
+	assertT := assert.New(t)
+
+	config := newRunConfig([]RunOption{WithWeights(8, 2), WithRandomMix(42)})
+	selections := takeSelections(newTaskSelector(config, 2), 10000)
+	assertT.Equal(selections, takeSelections(newTaskSelector(config, 2), 10000))
+
+	counts := make([]int, 2)
+	for _, idx := range selections {
+		counts[idx]++
+	}
+	assertT.InDelta(8000, counts[0], 200)
+	assertT.InDelta(2000, counts[1], 200)
+}
+
+func TestSelectorFailures(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Panics(func() { newTaskSelector(newRunConfig([]RunOption{WithWeights(1)}), 2) })
+	assertT.Panics(func() { newTaskSelector(newRunConfig([]RunOption{WithWeights(1, -1)}), 2) })
+	assertT.Panics(func() { newTaskSelector(newRunConfig([]RunOption{WithWeights(0, 0)}), 2) })
+}
+
+func TestRunTestWeighted(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func() error { return nil }
+	stats := RunTest([]TestTask{task, task}, TotalTests, Parallel, WithWeights(4, 1))
+
+	assertT.Equal(TotalTests*4/5, stats[0].Count)
+	assertT.Equal(TotalTests/5, stats[1].Count)
+}
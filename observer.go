@@ -0,0 +1,148 @@
+package perform
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Result of one completed run
+type RunResult struct {
+	Task     int // index of the task
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+	Retries  int
+}
+
+// Snapshot of test progress - time values in milliseconds
+type Progress struct {
+	Time       time.Time
+	Completed  int     // number of completed runs
+	Total      int     // total number of runs; zero if unknown
+	Throughput float64 // runs per second within the last period
+	P50        float64 // median latency within the last period
+	P99        float64 // 99th percentile of latency within the last period
+}
+
+// Receives results of completed runs and periodic progress snapshots while the test is running.
+// Test can be stopped early by cancelling its context.
+type Observer interface {
+	// Called for every completed measured run; may be called concurrently
+	OnRun(result RunResult)
+	// Called periodically and once at the end of the measured phase
+	OnProgress(progress Progress)
+}
+
+// Sets observer of the test and period of progress snapshots
+func WithObserver(observer Observer, period time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.observer = observer
+		c.progressPeriod = period
+	}
+}
+
+// Collects data for progress snapshots
+type progressTracker struct {
+	observer    Observer
+	period      time.Duration
+	lock        sync.Mutex
+	total       int
+	completed   int
+	window      []float64 // latencies of runs completed within the current period
+	windowStart time.Time
+	done        chan struct{}
+	stopped     sync.WaitGroup
+}
+
+func newProgressTracker(config runConfig) *progressTracker {
+	if config.observer == nil {
+		return nil
+	}
+	return &progressTracker{observer: config.observer, period: config.progressPeriod, window: make([]float64, 0),
+		done: make(chan struct{})}
+}
+
+// Starts measured phase of the test with the given (or unknown if zero) number of runs
+func (run *testRun) beginMeasurement(total int) {
+	run.startTime = time.Now()
+	if run.tracker != nil {
+		run.tracker.start(total, run.startTime)
+	}
+}
+
+// Duration of the measured phase; it ends when it is first queried after completion of all runs
+func (run *testRun) measuredTime() time.Duration {
+	if run.startTime.IsZero() {
+		return 0
+	}
+	if run.endTime.IsZero() {
+		run.endTime = time.Now()
+	}
+	return run.endTime.Sub(run.startTime)
+}
+
+func (pt *progressTracker) start(total int, now time.Time) {
+	pt.total = total
+	pt.windowStart = now
+	if pt.period <= 0 {
+		return
+	}
+
+	pt.stopped.Add(1)
+	go func() {
+		defer pt.stopped.Done()
+		ticker := time.NewTicker(pt.period)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				pt.observer.OnProgress(pt.snapshot(now))
+			case <-pt.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stops periodic snapshots and reports the final one
+func (pt *progressTracker) stop() {
+	if pt == nil || pt.windowStart.IsZero() {
+		return
+	}
+	close(pt.done)
+	pt.stopped.Wait()
+	pt.observer.OnProgress(pt.snapshot(time.Now()))
+}
+
+func (pt *progressTracker) add(result RunResult) {
+	if pt == nil {
+		return
+	}
+	pt.observer.OnRun(result)
+
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	pt.completed++
+	pt.window = append(pt.window, float64(result.Duration)/msecFctr)
+}
+
+// Creates snapshot and starts a new period
+func (pt *progressTracker) snapshot(now time.Time) Progress {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+
+	progress := Progress{Time: now, Completed: pt.completed, Total: pt.total}
+	if elapsed := now.Sub(pt.windowStart); elapsed > 0 {
+		progress.Throughput = float64(len(pt.window)) / elapsed.Seconds()
+	}
+	if len(pt.window) > 0 {
+		sort.Float64s(pt.window)
+		progress.P50 = percentile(pt.window, 50)
+		progress.P99 = percentile(pt.window, 99)
+	}
+
+	pt.window = pt.window[:0]
+	pt.windowStart = now
+	return progress
+}
@@ -0,0 +1,107 @@
+package perform
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func failingTask(context.Context) error {
+	time.Sleep(SleepTime)
+	return errTest
+}
+
+type testObserver struct {
+	lock      sync.Mutex
+	results   []RunResult
+	snapshots []Progress
+	onRun     func(RunResult)
+}
+
+func (o *testObserver) OnRun(result RunResult) {
+	o.lock.Lock()
+	o.results = append(o.results, result)
+	o.lock.Unlock()
+	if o.onRun != nil {
+		o.onRun(result)
+	}
+}
+
+func (o *testObserver) OnProgress(progress Progress) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.snapshots = append(o.snapshots, progress)
+}
+
+func TestObserverRuns(t *testing.T) {
+	assertT := assert.New(t)
+
+	observer := &testObserver{}
+	tasks := []ContextTask{sleepTask, failingTask}
+	stats := RunTestContext(context.Background(), tasks, TotalTests, Parallel,
+		WithObserver(observer, time.Hour), WithWarmupRuns(Parallel))
+
+	assertT.Equal(TotalTests, len(observer.results))
+	counts := make([]int, len(tasks))
+	for _, r := range observer.results {
+		counts[r.Task]++
+		assertT.False(r.Start.IsZero())
+		if r.Task == 0 {
+			assertT.NoError(r.Err)
+			assertT.GreaterOrEqual(r.Duration, SleepTime)
+		} else {
+			assertT.ErrorIs(r.Err, errTest)
+		}
+	}
+	for i, oneStat := range stats {
+		assertT.Equal(oneStat.Count, counts[i])
+	}
+
+	assertT.Equal(1, len(observer.snapshots))
+	final := observer.snapshots[0]
+	assertT.Equal(TotalTests, final.Completed)
+	assertT.Equal(TotalTests, final.Total)
+	assertT.Greater(final.Throughput, 0.0)
+	assertT.GreaterOrEqual(final.P99, final.P50)
+}
+
+func TestObserverProgress(t *testing.T) {
+	assertT := assert.New(t)
+
+	observer := &testObserver{}
+	tasks := []ContextTask{sleepTask}
+	RunTestFor(context.Background(), tasks, 10*SleepTime, 0, Parallel, WithObserver(observer, 3*SleepTime))
+
+	assertT.GreaterOrEqual(len(observer.snapshots), 3)
+	last := 0
+	for _, p := range observer.snapshots {
+		assertT.Equal(0, p.Total)
+		assertT.GreaterOrEqual(p.Completed, last)
+		last = p.Completed
+	}
+	assertT.Equal(len(observer.results), last)
+
+	first := observer.snapshots[0]
+	assertT.Greater(first.Throughput, 0.0)
+	assertT.GreaterOrEqual(first.P50, float64(SleepTime)/msecFctr)
+}
+
+func TestObserverAbort(t *testing.T) {
+	assertT := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	observer := &testObserver{onRun: func(r RunResult) {
+		if r.Err != nil {
+			cancel()
+		}
+	}}
+	tasks := []ContextTask{sleepTask, failingTask}
+	stats := RunTestContext(ctx, tasks, 10*TotalTests, Parallel, WithObserver(observer, 0))
+
+	assertT.True(stats[0].Incomplete)
+	assertT.Less(stats[0].Count+stats[1].Count, 10*TotalTests)
+}
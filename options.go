@@ -0,0 +1,91 @@
+package perform
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Optional parameters of a test
+type runConfig struct {
+	warmupRuns     int
+	warmupDuration time.Duration
+	weights        []int
+	randomMix      bool
+	mixSeed        int64
+	percentiles    []float64
+	histogram      *histogramConfig
+	errorClasses   []error
+	classifier     ErrorClassifier
+	separateFails  bool
+	runTimeout     time.Duration
+	hooks          runHooks
+	think          ThinkTime
+	thinkSeed      int64
+	pacing         time.Duration
+	observer       Observer
+	progressPeriod time.Duration
+	abortCriteria  []AbortCriterion
+	retry          *RetryPolicy
+	report         *RunReport
+	tickClock      bool
+	ticks          *TickCalibration // set when the test starts
+}
+
+// Option that modifies test execution
+type RunOption func(*runConfig)
+
+// Runs "n" warm-up runs before the measured ones. Their timings are available in `RunStats.Warmup`,
+// but are excluded from other statistics.
+func WithWarmupRuns(n int) RunOption {
+	return func(c *runConfig) { c.warmupRuns = n }
+}
+
+// Runs warm-up runs during the given period before the measured ones. Their timings are available
+// in `RunStats.Warmup`, but are excluded from other statistics.
+func WithWarmupDuration(d time.Duration) RunOption {
+	return func(c *runConfig) { c.warmupDuration = d }
+}
+
+// Limits duration of each run. When the timeout expires, the task context is cancelled and the run is recorded
+// as a failure with `ErrRunTimeout` error; the throttle slot is released without waiting for the task to return.
+// The abandoned task keeps the worker state of `Hooks`, and the worker continues with a new one.
+func WithRunTimeout(timeout time.Duration) RunOption {
+	return func(c *runConfig) { c.runTimeout = timeout }
+}
+
+func newRunConfig(opts []RunOption) runConfig {
+	var config runConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// Dispatches warm-up runs and waits for their completion
+func (run *testRun) warmUp(ctx context.Context, dispatch func(context.Context, <-chan time.Time, int) int) {
+	if run.config.warmupRuns <= 0 && run.config.warmupDuration <= 0 {
+		return
+	}
+
+	var stop <-chan time.Time
+	maxRuns := run.config.warmupRuns
+	if run.config.warmupDuration > 0 {
+		timer := time.NewTimer(run.config.warmupDuration)
+		defer timer.Stop()
+		stop = timer.C
+		if maxRuns <= 0 {
+			maxRuns = math.MaxInt
+		}
+	}
+
+	run.setWarmup(true)
+	defer run.setWarmup(false)
+	dispatch(ctx, stop, maxRuns)
+}
+
+func (run *testRun) setWarmup(on bool) {
+	for _, fixture := range run.fixtures {
+		fixture.inWarmup = on
+	}
+}
@@ -0,0 +1,80 @@
+package perform
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const WarmupRuns = 5
+
+func TestWarmupRuns(t *testing.T) {
+	assertT := assert.New(t)
+
+	var callsCount atomic.Int32
+	slowStartTask := func() error {
+		if callsCount.Add(1) <= WarmupRuns {
+			time.Sleep(SleepTime)
+		}
+		return nil
+	}
+
+	stats := RunTest([]TestTask{slowStartTask}, TotalTests, Parallel, WithWarmupRuns(WarmupRuns))
+
+	assertT.Equal(TotalTests+WarmupRuns, int(callsCount.Load()))
+	assertT.Equal(TotalTests, stats[0].Count)
+	assertT.Equal(WarmupRuns, len(stats[0].Warmup))
+	assertT.Less(stats[0].MaxTime, float64(SleepTime)/msecFctr)
+	for _, v := range stats[0].Warmup {
+		assertT.GreaterOrEqual(v, float64(SleepTime)/msecFctr)
+	}
+}
+
+func TestWarmupDuration(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats, elapsed := RunTestFor(context.Background(), []ContextTask{sleepTask}, 3*SleepTime, 0, 1,
+		WithWarmupDuration(2*SleepTime))
+
+	assertT.GreaterOrEqual(len(stats[0].Warmup), 2)
+	assertT.GreaterOrEqual(stats[0].Count, 3)
+	assertT.Less(elapsed, 5*SleepTime)
+}
+
+func TestWarmupRate(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats := RunTestRate(context.Background(), []ContextTask{sleepTask}, RateRuns, RateConfig{Rate: HighRate},
+		WithWarmupRuns(WarmupRuns))
+
+	assertT.Equal(RateRuns, stats[0].Count)
+	assertT.Equal(WarmupRuns, len(stats[0].Warmup))
+}
+
+func TestWarmupDurationRateDelayed(t *testing.T) {
+	assertT := assert.New(t)
+
+	slowTask := func(context.Context) error {
+		time.Sleep(5 * SleepTime)
+		return nil
+	}
+	config := RateConfig{Rate: HighRate, MaxInFlight: 1}
+	stats := RunTestRate(context.Background(), []ContextTask{slowTask}, 2, config, WithWarmupDuration(2*SleepTime))
+
+	// the run waiting for a slot at the end of warm-up is not started
+	assertT.Equal(1, len(stats[0].Warmup))
+	assertT.Equal(2, stats[0].Count)
+}
+
+func TestNoWarmup(t *testing.T) {
+	assertT := assert.New(t)
+
+	config := newRunConfig(nil)
+	assertT.Equal(runConfig{}, config)
+
+	stats := RunTest([]TestTask{func() error { return nil }}, TotalTests, Parallel)
+	assertT.Empty(stats[0].Warmup)
+}
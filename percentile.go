@@ -0,0 +1,49 @@
+package perform
+
+import "math"
+
+// Value of latency percentile
+type Percentile struct {
+	Level float64 `json,yaml:"level"` // percentile level in range [0, 100]
+	Value float64 `json,yaml:"value"` // time in milliseconds
+}
+
+// Percentile levels reported by default
+var DefaultPercentiles = []float64{90, 95, 99, 99.9}
+
+// Sets levels of percentiles reported in `RunStats.Percentiles`; no levels disable reporting.
+// Default levels are `DefaultPercentiles`.
+func WithPercentiles(levels ...float64) RunOption {
+	return func(c *runConfig) { c.percentiles = append([]float64{}, levels...) }
+}
+
+// Calculates percentile of sorted values with linear interpolation between closest ranks
+// (method R-7 of Hyndman and Fan, the default in Excel and NumPy). Level is in range [0, 100].
+func percentile(sorted []float64, level float64) float64 {
+	if len(sorted) == 0 || level < 0 || level > 100 {
+		return math.NaN()
+	}
+
+	rank := level / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+func calcPercentiles(sorted []float64, levels []float64) []Percentile {
+	ret := make([]Percentile, len(levels))
+	for i, level := range levels {
+		ret[i] = Percentile{Level: level, Value: percentile(sorted, level)}
+	}
+	return ret
+}
+
+// Finds value of percentile with the given level
+func (rs RunStats) Percentile(level float64) (float64, bool) {
+	for _, p := range rs.Percentiles {
+		if p.Level == level {
+			return p.Value, true
+		}
+	}
+	return math.NaN(), false
+}
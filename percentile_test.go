@@ -0,0 +1,59 @@
+package perform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assertT := assert.New(t)
+
+	sorted := []float64{1, 2, 3, 4, 5}
+	assertT.Equal(1.0, percentile(sorted, 0))
+	assertT.Equal(3.0, percentile(sorted, 50))
+	assertT.Equal(5.0, percentile(sorted, 100))
+	assertT.InDelta(4.6, percentile(sorted, 90), 1e-9)
+	assertT.InDelta(4.996, percentile(sorted, 99.9), 1e-9)
+	assertT.Equal(7.0, percentile([]float64{7}, 99))
+
+	assertT.True(math.IsNaN(percentile([]float64{}, 50)))
+	assertT.True(math.IsNaN(percentile(sorted, -1)))
+	assertT.True(math.IsNaN(percentile(sorted, 101)))
+}
+
+func TestCalcPercentiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	sorted := make([]float64, 1001)
+	for i := range sorted {
+		sorted[i] = float64(i)
+	}
+
+	expected := []Percentile{{90, 900}, {95, 950}, {99, 990}, {99.9, 999}}
+	actual := calcPercentiles(sorted, DefaultPercentiles)
+	assertT.Equal(len(expected), len(actual))
+	for i := range expected {
+		assertT.Equal(expected[i].Level, actual[i].Level)
+		assertT.InDelta(expected[i].Value, actual[i].Value, 1e-9)
+	}
+	assertT.Empty(calcPercentiles(sorted, []float64{}))
+}
+
+func TestWithPercentiles(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func() error { return nil }
+
+	stats := RunTest([]TestTask{task}, TotalTests, Parallel, WithPercentiles(50, 75))
+	assertT.Equal(2, len(stats[0].Percentiles))
+	p75, ok := stats[0].Percentile(75)
+	assertT.True(ok)
+	assertT.GreaterOrEqual(stats[0].MaxTime, p75)
+	_, ok = stats[0].Percentile(99)
+	assertT.False(ok)
+
+	stats = RunTest([]TestTask{task}, TotalTests, Parallel, WithPercentiles())
+	assertT.Empty(stats[0].Percentiles)
+}
@@ -1,6 +1,7 @@
 package perform
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -20,18 +21,101 @@ type RunStats struct {
 	StdDev  float64   `json,yaml:"stdev_time"`
 	Fails   int       `json,yaml:"fails"`
 	Values  []float64 `json,yaml:"times"`
+	// Tail latencies
+	Percentiles []Percentile `json,yaml:"percentiles"`
+	// Failures grouped by error class
+	Failures []FailureGroup `json,yaml:"failures"`
+	// Runs aborted because of run timeout (included in `Fails`)
+	Timeouts int `json,yaml:"timeouts"`
+	// Times of failed runs when kept separately from successful ones
+	FailValues []float64 `json,yaml:"fail_times"`
+	// Start time of the measured phase of the test
+	StartTime time.Time `json,yaml:"start_time"`
+	// Start offsets of runs from `StartTime` in milliseconds - same order as `Values`
+	Starts []float64 `json,yaml:"starts"`
+	// Latencies histogram - set instead of `Values` when recording in histograms
+	Histogram *Histogram `json,yaml:"histogram"`
+	// Warm-up times excluded from statistics
+	Warmup []float64 `json,yaml:"warmup_times"`
+	// Completed runs per second during the measured phase
+	Throughput float64 `json,yaml:"throughput"`
+	// Issued runs per second during the measured phase including dropped ones. Equals throughput in closed-loop
+	// tests, where think time and pacing lower both; in open-loop tests it approaches the arrival rate.
+	OfferedLoad float64 `json,yaml:"offered_load"`
+	// Open-loop runs that were not started because of in-flight limit
+	Dropped int `json,yaml:"dropped"`
+	// Open-loop runs that were started later than scheduled because of in-flight limit
+	Delayed int `json,yaml:"delayed"`
+	// Statistics of runs tagged with `TagRun`
+	ByTag map[string]RunStats `json,yaml:"by_tag"`
+	// Set when the test was stopped before all runs were dispatched
+	Incomplete bool `json,yaml:"incomplete"`
+	// Abort criterion that stopped the test
+	Abort *AbortEvent `json,yaml:"abort"`
+	// Total number of retries
+	Retries int `json,yaml:"retries"`
+	// Number of runs that were retried at least once
+	RetriedRuns int `json,yaml:"retried_runs"`
+	// Statistics of first attempts of runs when retries are enabled; other statistics include all attempts
+	FirstAttempt *RunStats `json,yaml:"first_attempt"`
+	// Calibration of the tick counter when runs are measured in ticks
+	TickClock *TickCalibration `json,yaml:"tick_clock"`
 }
 
 // Generic test task
 type TestTask func() error
 
+// Test task that observes cancellation of the test
+type ContextTask func(ctx context.Context) error
+
+// State of one test invocation
+type testRun struct {
+	config      runConfig
+	sema        chan struct{}
+	unlimited   bool      // open-loop test without in-flight limit - throttle only bounds the number of workers
+	parked      int       // throttle slots held by dispatcher to lower concurrency
+	startTime   time.Time // start of the measured phase
+	endTime     time.Time // end of the measured phase - set when statistics are calculated
+	selector    taskSelector
+	queue       chan runJob // dispatch queue of workers
+	workers     int         // number of started workers
+	workerGroup sync.WaitGroup
+	suiteReady  bool          // suite setup hook succeeded
+	aborted     chan struct{} // closed when dispatching should stop
+	abortOnce   sync.Once
+	waitGroup   sync.WaitGroup
+	fixtures    []*taskFixture
+	tracker     *progressTracker // nil without observer
+	monitor     *abortMonitor    // nil without abort criteria
+	maxInFlight int              // highest number of concurrent runs in the measured phase
+	fixtureSets [][]*taskFixture // all created fixtures - several sets in load profiles
+}
+
 type taskFixture struct {
+	config    *runConfig
+	abort     func()          // stops dispatching of new runs
 	sema      *chan struct{}  // threads number throttle - shared
 	waitGroup *sync.WaitGroup // completion flag - shared
 	lock      sync.Mutex      // `runtimes` guard
-	task      TestTask
+	task      ContextTask
 	runtimes  []time.Duration
+	starts    []time.Time
+	hist      *Histogram // replaces `runtimes` when set
+	warmup    []time.Duration
+	inWarmup  bool // switched by dispatcher between phases
 	fails     int
+	timeouts  int
+	failures  []FailureGroup
+	failTimes []time.Duration         // failed runs times when kept separately
+	byTag     map[string]*taskFixture // runs tagged with `TagRun` - guarded by parent's lock
+	dropped   int                     // updated by dispatcher only
+	delayed   int                     // updated by dispatcher only
+	index     int                     // position in the list of tasks
+	tracker   *progressTracker        // nil without observer
+	monitor   *abortMonitor           // nil without abort criteria
+	retries   int
+	retried   int          // number of retried runs
+	first     *taskFixture // first attempts of runs when retries are enabled
 }
 
 // No data struct
@@ -39,7 +123,7 @@ var ND = struct{}{}
 
 const msecFctr = float64(time.Millisecond)
 
-// Runs concurrently several tasks
+// Runs concurrently several tasks. Runs are executed by a pool of at most "concurrent" long-lived workers.
 //
 //   - tasks - tasks to run
 //
@@ -47,88 +131,385 @@ const msecFctr = float64(time.Millisecond)
 //
 //   - concurrent - number of concurrent tasks (< totalRuns)
 //
+//   - opts - optional parameters like warm-up or tasks mix
+//
 //     return time statistics for each task
-func RunTest(tasks []TestTask, totalRuns int, concurrent int) []RunStats {
-	waitGroup := new(sync.WaitGroup)
-	sema := make(chan struct{}, concurrent)
-	fixtures := make([]*taskFixture, len(tasks))
-	for i, task := range tasks {
-		fixtures[i] = createFixture(task, &sema, waitGroup)
+func RunTest(tasks []TestTask, totalRuns int, concurrent int, opts ...RunOption) []RunStats {
+	return RunTestContext(context.Background(), wrapTasks(tasks), totalRuns, concurrent, opts...)
+}
+
+// Same as `RunTest`, but stops dispatching new runs when the context is cancelled or its deadline passes.
+// Tasks receive the context and are expected to abandon their work when it is done.
+//
+// Statistics of runs completed so far are returned with the `Incomplete` flag set.
+func RunTestContext(ctx context.Context, tasks []ContextTask, totalRuns int, concurrent int, opts ...RunOption) []RunStats {
+	run := newTestRun(tasks, concurrent, newRunConfig(opts))
+	defer run.finish(ctx)
+	if err := run.setupSuite(ctx); err != nil {
+		stats := run.calcStats(run.fixtures)
+		markSetupFailure(stats, err)
+		return stats
 	}
 
-	for i := 0; i < totalRuns; i++ {
-		idx := i % len(tasks)
-		waitGroup.Add(1)
-		go runOneTask(fixtures[idx])
+	run.warmUp(ctx, run.dispatch)
+	run.beginMeasurement(totalRuns)
+	dispatched := run.dispatch(ctx, nil, totalRuns)
+
+	stats := run.calcStats(run.fixtures)
+	if dispatched < totalRuns {
+		markIncomplete(stats)
 	}
+	return stats
+}
 
-	waitGroup.Wait()
+// Runs tasks concurrently for the given wall-clock duration
+//
+//   - ctx - context passed to tasks; its cancellation stops the test prematurely
+//
+//   - tasks - tasks to run
+//
+//   - duration - time period while new runs are dispatched
+//
+//   - maxRuns - optional limit of total runs (unlimited if <= 0)
+//
+//   - concurrent - number of concurrent tasks
+//
+//   - opts - optional parameters like warm-up
+//
+//     return time statistics for each task and elapsed time including completion of in-flight runs
+func RunTestFor(ctx context.Context, tasks []ContextTask, duration time.Duration, maxRuns int, concurrent int,
+	opts ...RunOption) ([]RunStats, time.Duration) {
+	total := max(maxRuns, 0)
+	if maxRuns <= 0 {
+		maxRuns = math.MaxInt
+	}
 
-	return calcStats(fixtures)
+	run := newTestRun(tasks, concurrent, newRunConfig(opts))
+	defer run.finish(ctx)
+	if err := run.setupSuite(ctx); err != nil {
+		stats := run.calcStats(run.fixtures)
+		markSetupFailure(stats, err)
+		return stats, 0
+	}
+
+	run.warmUp(ctx, run.dispatch)
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	run.beginMeasurement(total)
+	run.dispatch(ctx, timer.C, maxRuns)
+	elapsedTime := time.Since(run.startTime)
+
+	stats := run.calcStats(run.fixtures)
+	if ctx.Err() != nil || run.isAborted() {
+		markIncomplete(stats)
+	}
+	return stats, elapsedTime
 }
 
-// Compares two series of tests and calculates probabilities that latencies in the second series
-// are larger using t-test statistics.
+// Compares two series of tests and calculates probabilities (p-values) of the alternative hypothesis
+// about latencies of each task. By default it uses Student's t-test with the alternative hypothesis that
+// latencies in the first series are greater (`LocationGreater`) at significance level 0.05.
 //
-// Statistics "stat1" and "stats2" should have same number od tests; run counts in test pairs are not required to be equal,
-// but they should be larger than 1.
-func CalcPvals(stats1, stats2 []RunStats) ([]float64, error) {
+// Statistics "stat1" and "stats2" should have same number od tests; run counts in test pairs are not required to be equal
+// (except for the paired test), but they should be larger than 1. Paired and rank tests use raw values of runs.
+func CalcPvals(stats1, stats2 []RunStats, opts ...PvalOption) ([]PvalResult, error) {
 	if len(stats1) != len(stats2) {
 		return nil, errors.New("different size of tasks")
 	}
 
-	pVals := make([]float64, 0, len(stats1))
+	config := newPvalConfig(opts)
+	results := make([]PvalResult, 0, len(stats1))
 	for i := range stats1 {
-		tSample1 := timeStat2Tstat(stats1[i])
-		tSample2 := timeStat2Tstat(stats2[i])
-
-		tRes, err := TwoSampleTTest(tSample1, tSample2, LocationGreater)
+		res, err := config.compare(stats1[i], stats2[i])
 		if err != nil {
-			return nil, fmt.Errorf("invalid statistics data in test #%d: %v", i, err)
+			return nil, fmt.Errorf("invalid statistics data in test #%d: %w", i, err)
 		}
 
-		pVals = append(pVals, tRes.P)
+		results = append(results, res)
 	}
+	config.decide(results)
 
-	return pVals, nil
+	return results, nil
 }
 
-func createFixture(task TestTask, sema *chan struct{}, waitGroup *sync.WaitGroup) *taskFixture {
+func wrapTasks(tasks []TestTask) []ContextTask {
+	ret := make([]ContextTask, len(tasks))
+	for i, task := range tasks {
+		ret[i] = func(context.Context) error { return task() }
+	}
+	return ret
+}
+
+func newTestRun(tasks []ContextTask, concurrent int, config runConfig) *testRun {
+	config.ticks = newTickClock(config)
+	run := &testRun{config: config, sema: make(chan struct{}, concurrent), queue: make(chan runJob),
+		aborted: make(chan struct{}), tracker: newProgressTracker(config)}
+	run.monitor = newAbortMonitor(config.abortCriteria, run.abort)
+	run.selector = newTaskSelector(config, len(tasks))
+	run.fixtures = run.createFixtures(tasks)
+	return run
+}
+
+func (run *testRun) createFixtures(tasks []ContextTask) []*taskFixture {
+	fixtures := make([]*taskFixture, len(tasks))
+	for i, task := range tasks {
+		fixtures[i] = createFixture(task, &run.sema, &run.waitGroup)
+		fixtures[i].index = i
+		fixtures[i].config = &run.config
+		fixtures[i].tracker = run.tracker
+		fixtures[i].monitor = run.monitor
+		fixtures[i].abort = run.abort
+		if hc := run.config.histogram; hc != nil {
+			fixtures[i].hist = NewHistogram(hc.lowest, hc.highest, hc.significantDigits)
+		}
+	}
+	run.fixtureSets = append(run.fixtureSets, fixtures)
+	return fixtures
+}
+
+// Dispatches runs in the order defined by task selector until "maxRuns" are started, the context is done or "stop" fires.
+// Waits for completion of dispatched runs; returns number of dispatched runs.
+func (run *testRun) dispatch(ctx context.Context, stop <-chan time.Time, maxRuns int) int {
+	dispatched := run.dispatchRuns(ctx, stop, maxRuns)
+	run.waitGroup.Wait()
+	return dispatched
+}
+
+// Same as `dispatch`, but does not wait for completion of dispatched runs
+func (run *testRun) dispatchRuns(ctx context.Context, stop <-chan time.Time, maxRuns int) int {
+	dispatched := 0
+	for ; dispatched < maxRuns && run.acquireSlot(ctx, stop); dispatched++ {
+		run.submit(ctx, runJob{fixture: run.fixtures[run.selector()]})
+	}
+	return dispatched
+}
+
+func createFixture(task ContextTask, sema *chan struct{}, waitGroup *sync.WaitGroup) *taskFixture {
 	var fixture taskFixture
 	fixture.sema = sema
 	fixture.waitGroup = waitGroup
 	fixture.lock = sync.Mutex{}
 	fixture.task = task
 	fixture.runtimes = make([]time.Duration, 0)
+	fixture.starts = make([]time.Time, 0)
+	fixture.warmup = make([]time.Duration, 0)
+	fixture.config = new(runConfig)
+	fixture.abort = func() {}
 	return &fixture
 }
 
-func runOneTask(fixture *taskFixture) {
-	*fixture.sema <- ND
+// Waits for a free slot in the throttle; returns false if the context is done, "stop" fires or the test is aborted first
+func (run *testRun) acquireSlot(ctx context.Context, stop <-chan time.Time) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-run.aborted:
+		return false
+	default:
+	}
+
+	select {
+	case run.sema <- ND:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-run.aborted:
+		return false
+	}
+}
+
+// Stops dispatching of new runs; in-flight runs are completed
+func (run *testRun) abort() {
+	run.abortOnce.Do(func() { close(run.aborted) })
+}
+
+func (run *testRun) isAborted() bool {
+	select {
+	case <-run.aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Runs the task in a slot acquired by the dispatcher; execution time is counted from now
+func runOneTask(ctx context.Context, fixture *taskFixture) {
+	runScheduledTask(ctx, fixture, time.Time{})
+}
+
+// Runs the task in a slot acquired by the dispatcher; execution time is counted from "start" or from now if it is zero
+func runScheduledTask(ctx context.Context, fixture *taskFixture, start time.Time) {
+	scheduled := !start.IsZero()
+	if !scheduled {
+		start = time.Now()
+	}
 	defer func() { <-*fixture.sema }()
+	defer fixture.pause(ctx, start)
 	defer fixture.waitGroup.Done()
 
-	start := time.Now()
-	err := fixture.task()
-	execTime := time.Since(start)
+	rc := &runContext{}
+	ctx = context.WithValue(ctx, runContextKey{}, rc)
+
+	hookTime, err := fixture.beforeRun(ctx)
+	a := attempts{firstErr: err}
+	if err == nil {
+		a, err = fixture.executeWithRetries(ctx)
+	}
+	execTime := time.Since(start) - hookTime
+	firstTime := execTime
+	if !a.firstEnd.IsZero() {
+		firstTime = a.firstEnd.Sub(start) - hookTime
+	}
+	if ticks := fixture.config.ticks; ticks != nil && !a.firstEnd.IsZero() {
+		var wait time.Duration
+		if scheduled {
+			wait = max(a.begin.Sub(start)-hookTime, 0)
+		}
+		execTime = wait + ticks.duration(a.ticks)
+		firstTime = wait + ticks.duration(a.firstTicks)
+	}
+	fixture.afterRun(ctx, err)
+
+	if errors.Is(err, ErrFeederExhausted) {
+		fixture.abort()
+		return
+	}
+	if fixture.record(start, execTime, err, rc.getTag(), firstTime, a) {
+		result := RunResult{Task: fixture.index, Start: start, Duration: execTime, Err: err, Retries: a.retries}
+		fixture.tracker.add(result)
+		fixture.monitor.check(result)
+	}
+}
+
+// Calls the task observing run timeout. A timed-out task is abandoned - its context is cancelled,
+// but its completion is not awaited; the worker state is retired together with the task.
+func (fixture *taskFixture) execute(ctx context.Context) error {
+	timeout := fixture.config.runTimeout
+	if timeout <= 0 {
+		return fixture.task(ctx)
+	}
 
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	wc, _ := ctx.Value(workerContextKey{}).(*workerContext)
+	if wc != nil {
+		// retry after an abandoned attempt
+		fixture.config.renewState(ctx)
+		// the task keeps its own view of worker state in case it is abandoned
+		runState := *wc
+		runCtx = context.WithValue(runCtx, workerContextKey{}, &runState)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fixture.task(runCtx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-runCtx.Done():
+		select {
+		case err = <-done:
+		default:
+			err = runCtx.Err()
+			if wc != nil {
+				fixture.config.retireState(ctx, wc, done)
+			}
+		}
+	}
+	if err != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return ErrRunTimeout
+	}
+	return err
+}
+
+// Stores results of one run; returns false for warm-up runs
+func (fixture *taskFixture) record(start time.Time, execTime time.Duration, err error, tag string,
+	firstTime time.Duration, a attempts) bool {
 	fixture.lock.Lock()
-	fixture.runtimes = append(fixture.runtimes, execTime)
+	defer fixture.lock.Unlock()
+	if fixture.inWarmup {
+		fixture.warmup = append(fixture.warmup, execTime)
+		return false
+	}
+	fixture.store(start, execTime, err)
+	fixture.storeAttempts(start, firstTime, a)
+	if tag != "" {
+		tagged := fixture.tagFixture(tag)
+		tagged.store(start, execTime, err)
+		tagged.storeAttempts(start, firstTime, a)
+	}
+	return true
+}
+
+// Adds results of one run to collected data; should be called under lock
+func (fixture *taskFixture) store(start time.Time, execTime time.Duration, err error) {
 	if err != nil {
 		fixture.fails++
+		if errors.Is(err, ErrRunTimeout) {
+			fixture.timeouts++
+		}
+		fixture.failures = addFailure(fixture.failures, fixture.config.classifyError(err), err.Error())
+		if fixture.config.separateFails {
+			fixture.failTimes = append(fixture.failTimes, execTime)
+			return
+		}
+	}
+	if fixture.hist != nil {
+		fixture.hist.Record(execTime)
+	} else {
+		fixture.runtimes = append(fixture.runtimes, execTime)
+		fixture.starts = append(fixture.starts, start)
 	}
-	fixture.lock.Unlock()
 }
 
-func calcStats(fixtures []*taskFixture) []RunStats {
+func (run *testRun) calcStats(fixtures []*taskFixture) []RunStats {
+	stats := calcStats(fixtures, run.config.percentiles, run.startTime)
+	setThroughput(stats, run.measuredTime())
+	for i := range stats {
+		stats[i].TickClock = run.config.ticks
+	}
+	if event := run.monitor.firedEvent(); event != nil {
+		for i := range stats {
+			stats[i].Abort = event
+		}
+		markIncomplete(stats)
+	}
+	return stats
+}
+
+// Calculates statistics for each fixture; nil "levels" stand for default percentiles.
+// Run start times are converted to offsets from "origin".
+func calcStats(fixtures []*taskFixture, levels []float64, origin time.Time) []RunStats {
+	if levels == nil {
+		levels = DefaultPercentiles
+	}
 	ret := make([]RunStats, 0)
 
 	for _, fixture := range fixtures {
+		if fixture.hist != nil {
+			testStats := fixture.hist.Stats(levels)
+			testStats.Histogram = fixture.hist
+			testStats.StartTime = origin
+			testStats.Warmup = durations2msec(fixture.warmup)
+			fixture.setCommonStats(&testStats, levels, origin)
+			ret = append(ret, testStats)
+			continue
+		}
+
 		var testStats RunStats
-		testStats.Values = make([]float64, len(fixture.runtimes))
-		for i, t := range fixture.runtimes {
-			testStats.Values[i] = float64(t) / msecFctr
+		testStats.Values = durations2msec(fixture.runtimes)
+		testStats.StartTime = origin
+		testStats.Starts = make([]float64, len(fixture.starts))
+		for i, start := range fixture.starts {
+			testStats.Starts[i] = float64(start.Sub(origin)) / msecFctr
 		}
+		testStats.Warmup = durations2msec(fixture.warmup)
 
 		sorttimes := make([]float64, len(fixture.runtimes))
 		copy(sorttimes, testStats.Values)
@@ -136,18 +517,58 @@ func calcStats(fixtures []*taskFixture) []RunStats {
 
 		testCount := len(sorttimes)
 		testStats.Count = len(sorttimes)
-		testStats.Fails = fixture.fails
+		fixture.setCommonStats(&testStats, levels, origin)
+		if testCount == 0 {
+			ret = append(ret, testStats)
+			continue
+		}
 		testStats.AvgTime = mean(sorttimes)
 		testStats.MinTime = sorttimes[0]
 		testStats.MedTime = sorttimes[testCount/2]
 		testStats.MaxTime = sorttimes[testCount-1]
 		testStats.StdDev = math.Sqrt(variance(sorttimes))
+		testStats.Percentiles = calcPercentiles(sorttimes, levels)
 
 		ret = append(ret, testStats)
 	}
 	return ret
 }
 
+func (fixture *taskFixture) setCommonStats(stats *RunStats, levels []float64, origin time.Time) {
+	stats.Fails = fixture.fails
+	stats.Timeouts = fixture.timeouts
+	stats.Failures = fixture.failures
+	stats.FailValues = durations2msec(fixture.failTimes)
+	if len(fixture.byTag) > 0 {
+		stats.ByTag = make(map[string]RunStats, len(fixture.byTag))
+		for tag, tagged := range fixture.byTag {
+			stats.ByTag[tag] = calcStats([]*taskFixture{tagged}, levels, origin)[0]
+		}
+	}
+	stats.Dropped = fixture.dropped
+	stats.Delayed = fixture.delayed
+	stats.Retries = fixture.retries
+	stats.RetriedRuns = fixture.retried
+	if fixture.first != nil {
+		firstStats := calcStats([]*taskFixture{fixture.first}, levels, origin)[0]
+		stats.FirstAttempt = &firstStats
+	}
+}
+
+func durations2msec(durations []time.Duration) []float64 {
+	ret := make([]float64, len(durations))
+	for i, t := range durations {
+		ret[i] = float64(t) / msecFctr
+	}
+	return ret
+}
+
+func markIncomplete(stats []RunStats) {
+	for i := range stats {
+		stats[i].Incomplete = true
+	}
+}
+
 // Ignore silently
 func IgnoreErr[T any](f func() (T, error), defVal T) T {
 	val, err := f()
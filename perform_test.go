@@ -1,6 +1,7 @@
 package perform
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"sync"
@@ -26,7 +27,7 @@ var (
 func TestCreateFixture(t *testing.T) {
 	assertT := assert.New(t)
 
-	var task TestTask = func() error { return nil }
+	var task ContextTask = func(context.Context) error { return nil }
 
 	fixture := createFixture(task, &sema, &waitGroup)
 
@@ -38,13 +39,14 @@ func TestCreateFixture(t *testing.T) {
 func TestOneTaskSuccess(t *testing.T) {
 	assertT := assert.New(t)
 
-	taskOk := func() error {
+	taskOk := func(context.Context) error {
 		time.Sleep(SleepTime)
 		return nil
 	}
 	fixture := createFixture(taskOk, &sema, &waitGroup)
+	sema <- ND
 	waitGroup.Add(1)
-	runOneTask(fixture)
+	runOneTask(context.Background(), fixture)
 
 	assertT.Equal(1, len(fixture.runtimes))
 	assertT.Equal(0, fixture.fails)
@@ -54,13 +56,14 @@ func TestOneTaskSuccess(t *testing.T) {
 func TestOneTaskFailure(t *testing.T) {
 	assertT := assert.New(t)
 
-	taskFail := func() error {
+	taskFail := func(context.Context) error {
 		time.Sleep(SleepTime)
 		return errors.New("")
 	}
 	fixture := createFixture(taskFail, &sema, &waitGroup)
+	sema <- ND
 	waitGroup.Add(1)
-	runOneTask(fixture)
+	runOneTask(context.Background(), fixture)
 
 	assertT.Equal(1, len(fixture.runtimes))
 	assertT.Equal(1, fixture.fails)
@@ -71,7 +74,7 @@ func TestCalcStats(t *testing.T) {
 	assertT := assert.New(t)
 
 	aFixture := taskFixture{runtimes: []time.Duration{9000000, 8000000, 7000000, 6000000, 5000000, 4000000, 3000000, 2000000, 1000000, 0}, fails: 3}
-	stats := calcStats([]*taskFixture{&aFixture})
+	stats := calcStats([]*taskFixture{&aFixture}, nil, time.Now())
 
 	assertT.Equal(1, len(stats))
 	oneStat := stats[0]
@@ -83,6 +86,8 @@ func TestCalcStats(t *testing.T) {
 	assertT.Equal(9.0, oneStat.MaxTime)
 	assertT.Equal(3.0276503540974917, oneStat.StdDev)
 	assertT.Equal([]float64{9.0, 8.0, 7.0, 6.0, 5.0, 4.0, 3.0, 2.0, 1.0, 0.0}, oneStat.Values)
+	assertT.Equal(len(DefaultPercentiles), len(oneStat.Percentiles))
+	assertT.InDelta(8.1, oneStat.Percentiles[0].Value, 1e-9)
 }
 
 func TestRunTest(t *testing.T) {
@@ -103,6 +108,135 @@ func TestRunTest(t *testing.T) {
 	oneStat := stats[0]
 	assertT.Equal(TotalTests, oneStat.Count)
 	assertT.GreaterOrEqual(oneStat.MinTime, float64(SleepTime)/msecFctr)
+	assertT.False(oneStat.Incomplete)
+}
+
+func TestCalcStatsNoRuns(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats := calcStats([]*taskFixture{{runtimes: []time.Duration{}}}, nil, time.Now())
+
+	assertT.Equal(1, len(stats))
+	assertT.Equal(0, stats[0].Count)
+	assertT.Empty(stats[0].Values)
+}
+
+func TestRunTestContextCancel(t *testing.T) {
+	assertT := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var callsCount atomic.Int32
+
+	task := func(ctx context.Context) error {
+		if callsCount.Add(1) == Parallel {
+			cancel()
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	stats := RunTestContext(ctx, []ContextTask{task}, TotalTests, Parallel)
+
+	assertT.Equal(Parallel, int(callsCount.Load()))
+	assertT.Equal(1, len(stats))
+	assertT.Equal(Parallel, stats[0].Count)
+	assertT.Equal(Parallel, stats[0].Fails)
+	assertT.True(stats[0].Incomplete)
+}
+
+func TestRunTestContextDeadline(t *testing.T) {
+	assertT := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*SleepTime)
+	defer cancel()
+
+	task := func(context.Context) error {
+		time.Sleep(SleepTime)
+		return nil
+	}
+
+	stats := RunTestContext(ctx, []ContextTask{task, task}, TotalTests, 1)
+
+	assertT.Equal(2, len(stats))
+	assertT.Less(stats[0].Count+stats[1].Count, TotalTests)
+	assertT.True(stats[0].Incomplete)
+	assertT.True(stats[1].Incomplete)
+}
+
+func TestRunTestFor(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func(context.Context) error {
+		time.Sleep(SleepTime)
+		return nil
+	}
+
+	stats, elapsed := RunTestFor(context.Background(), []ContextTask{task}, 5*SleepTime, 0, Parallel)
+
+	assertT.Equal(1, len(stats))
+	assertT.GreaterOrEqual(stats[0].Count, 4*Parallel)
+	assertT.False(stats[0].Incomplete)
+	assertT.GreaterOrEqual(elapsed, 5*SleepTime)
+	assertT.Less(elapsed, 10*SleepTime)
+}
+
+func TestRunTestForMaxRuns(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func(context.Context) error { return nil }
+
+	stats, _ := RunTestFor(context.Background(), []ContextTask{task, task}, time.Minute, TotalTests, Parallel)
+
+	assertT.Equal(TotalTests/2, stats[0].Count)
+	assertT.Equal(TotalTests/2, stats[1].Count)
+	assertT.False(stats[0].Incomplete)
+}
+
+func TestRunTimeout(t *testing.T) {
+	assertT := assert.New(t)
+
+	hang := make(chan struct{})
+	defer close(hang)
+
+	var callsCount atomic.Int32
+	var cancelled atomic.Int32
+	task := func(ctx context.Context) error {
+		switch callsCount.Add(1) % 5 {
+		case 0: // never returns
+			<-hang
+		case 1: // respects context
+			<-ctx.Done()
+			cancelled.Add(1)
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	startTime := time.Now()
+	stats := RunTestContext(context.Background(), []ContextTask{task}, TotalTests, Parallel, WithRunTimeout(SleepTime))
+
+	oneStat := stats[0]
+	assertT.Less(time.Since(startTime), time.Duration(TotalTests)*SleepTime)
+	assertT.Equal(TotalTests, oneStat.Count)
+	assertT.Equal(2*TotalTests/5, oneStat.Timeouts)
+	assertT.Equal(2*TotalTests/5, oneStat.Fails)
+	assertT.Eventually(func() bool { return int(cancelled.Load()) == TotalTests/5 }, time.Second, time.Millisecond)
+	assertT.Equal([]FailureGroup{{Class: ErrRunTimeout.Error(), Count: 2 * TotalTests / 5, Samples: []string{ErrRunTimeout.Error()}}},
+		oneStat.Failures)
+	assertT.GreaterOrEqual(oneStat.MaxTime, float64(SleepTime)/msecFctr)
+	assertT.Less(oneStat.MinTime, float64(SleepTime)/msecFctr)
+}
+
+func TestRunTimeoutCancel(t *testing.T) {
+	assertT := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fixture := createFixture(func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }, &sema, &waitGroup)
+	fixture.config.runTimeout = time.Minute
+
+	assertT.ErrorIs(fixture.execute(ctx), context.Canceled)
 }
 
 func TestIgnoreErr(t *testing.T) {
@@ -148,7 +282,8 @@ func TestCalcPvals(t *testing.T) {
 
 	probs, err := CalcPvals([]RunStats{stat1}, []RunStats{stat2})
 	assertT.NoError(err)
-	assertT.Equal(0.9691777120698255, probs[0])
+	assertT.Equal(0.9691777120698255, probs[0].P)
+	assertT.False(probs[0].Reject)
 
 	stat2.Count = 1
 	_, err = CalcPvals([]RunStats{stat1}, []RunStats{stat2})
@@ -0,0 +1,154 @@
+package perform
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Stage of a load profile - number of concurrent tasks kept during a period
+type LoadStage struct {
+	Concurrency int
+	Duration    time.Duration
+}
+
+// Sequence of load stages
+type LoadProfile []LoadStage
+
+// Statistics for one stage of a load profile
+type StageStats struct {
+	Concurrency int           `json,yaml:"concurrency"`
+	Start       time.Duration `json,yaml:"start"` // offset from the test start
+	Duration    time.Duration `json,yaml:"duration"`
+	Stats       []RunStats    `json,yaml:"stats"`
+}
+
+// Creates profile from explicit stages
+func SteppedProfile(stages ...LoadStage) LoadProfile {
+	return LoadProfile(stages)
+}
+
+// Creates profile that changes concurrency by one from "from" to "to" within the given period.
+// Each concurrency level is kept for equal time.
+func LinearRamp(from, to int, duration time.Duration) LoadProfile {
+	step := 1
+	if to < from {
+		step = -1
+	}
+	levels := (to-from)*step + 1
+	stageDuration := duration / time.Duration(levels)
+
+	profile := make(LoadProfile, 0, levels)
+	for c := from; c != to+step; c += step {
+		profile = append(profile, LoadStage{Concurrency: c, Duration: stageDuration})
+	}
+	return profile
+}
+
+// Creates profile with a short burst of "peak" concurrency surrounded by "base" concurrency stages
+func SpikeProfile(base, peak int, baseDuration, spikeDuration time.Duration) LoadProfile {
+	return LoadProfile{
+		{Concurrency: base, Duration: baseDuration},
+		{Concurrency: peak, Duration: spikeDuration},
+		{Concurrency: base, Duration: baseDuration},
+	}
+}
+
+// Total duration of the profile
+func (p LoadProfile) Duration() time.Duration {
+	var total time.Duration
+	for _, stage := range p {
+		total += stage.Duration
+	}
+	return total
+}
+
+func (p LoadProfile) maxConcurrency() int {
+	ret := 0
+	for _, stage := range p {
+		ret = max(ret, stage.Concurrency)
+	}
+	return ret
+}
+
+// Runs tasks following the load profile - number of concurrent tasks changes from stage to stage.
+// Runs are attributed to the stage in which they were dispatched. Runs started at the end of a stage
+// continue when the next stage begins.
+//
+//   - ctx - context passed to tasks; its cancellation stops the test prematurely
+//
+//   - tasks - tasks to run
+//
+//   - profile - stages of the test
+//
+//   - opts - optional parameters like warm-up (executed with concurrency of the first stage, but at least one,
+//     and reported in its statistics)
+//
+//     return time statistics for each stage and task
+func RunTestProfile(ctx context.Context, tasks []ContextTask, profile LoadProfile, opts ...RunOption) []StageStats {
+	run := newTestRun(tasks, max(profile.maxConcurrency(), 1), newRunConfig(opts))
+	defer run.finish(ctx)
+
+	stageFixtures := make([][]*taskFixture, len(profile))
+	stageStarts := make([]time.Duration, len(profile))
+	for i := range profile {
+		if i == 0 {
+			stageFixtures[i] = run.fixtures // keeps warm-up results
+		} else {
+			stageFixtures[i] = run.createFixtures(tasks)
+		}
+	}
+
+	setupErr := run.setupSuite(ctx)
+	if setupErr == nil && len(profile) > 0 && run.setConcurrency(ctx, max(profile[0].Concurrency, 1)) {
+		run.warmUp(ctx, run.dispatch)
+	}
+
+	run.beginMeasurement(0)
+	for i, stage := range profile {
+		if setupErr != nil {
+			break
+		}
+		stageStarts[i] = time.Since(run.startTime)
+		if !run.setConcurrency(ctx, stage.Concurrency) {
+			break
+		}
+		run.fixtures = stageFixtures[i]
+		run.dispatchFor(ctx, stage.Duration)
+	}
+	run.waitGroup.Wait()
+
+	ret := make([]StageStats, len(profile))
+	for i, stage := range profile {
+		ret[i] = StageStats{Concurrency: stage.Concurrency, Start: stageStarts[i], Duration: stage.Duration,
+			Stats: run.calcStats(stageFixtures[i])}
+		setThroughput(ret[i].Stats, stage.Duration)
+		if setupErr != nil {
+			markSetupFailure(ret[i].Stats, setupErr)
+		} else if ctx.Err() != nil || run.isAborted() {
+			markIncomplete(ret[i].Stats)
+		}
+	}
+	return ret
+}
+
+func (run *testRun) dispatchFor(ctx context.Context, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	run.dispatchRuns(ctx, timer.C, math.MaxInt)
+}
+
+// Changes throttle capacity by parking or releasing slots. Lowering concurrency waits for in-flight runs
+// to complete. Returns false if the context is done or the test is aborted.
+func (run *testRun) setConcurrency(ctx context.Context, n int) bool {
+	target := cap(run.sema) - max(n, 0)
+	for ; run.parked < target; run.parked++ {
+		if !run.acquireSlot(ctx, nil) {
+			return false
+		}
+	}
+	for ; run.parked > target; run.parked-- {
+		<-run.sema
+	}
+	return true
+}
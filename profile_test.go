@@ -0,0 +1,119 @@
+package perform
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tracks maximal number of concurrently running tasks
+type concurTracker struct {
+	lock    sync.Mutex
+	current int
+	peak    int
+}
+
+func (ct *concurTracker) task(context.Context) error {
+	ct.lock.Lock()
+	ct.current++
+	ct.peak = max(ct.peak, ct.current)
+	ct.lock.Unlock()
+
+	time.Sleep(SleepTime)
+
+	ct.lock.Lock()
+	ct.current--
+	ct.lock.Unlock()
+	return nil
+}
+
+func TestProfileBuilders(t *testing.T) {
+	assertT := assert.New(t)
+
+	assertT.Equal(LoadProfile{{1, time.Second}, {4, time.Second}},
+		SteppedProfile(LoadStage{1, time.Second}, LoadStage{4, time.Second}))
+	assertT.Equal(LoadProfile{{1, time.Second}, {2, time.Second}, {3, time.Second}}, LinearRamp(1, 3, 3*time.Second))
+	assertT.Equal(LoadProfile{{3, time.Second}, {2, time.Second}, {1, time.Second}}, LinearRamp(3, 1, 3*time.Second))
+	assertT.Equal(LoadProfile{{5, time.Second}}, LinearRamp(5, 5, time.Second))
+
+	spike := SpikeProfile(2, 20, time.Second, 100*time.Millisecond)
+	assertT.Equal(LoadProfile{{2, time.Second}, {20, 100 * time.Millisecond}, {2, time.Second}}, spike)
+	assertT.Equal(2100*time.Millisecond, spike.Duration())
+	assertT.Equal(20, spike.maxConcurrency())
+}
+
+func TestRunTestProfile(t *testing.T) {
+	assertT := assert.New(t)
+
+	var tracker concurTracker
+
+	profile := SteppedProfile(LoadStage{1, 5 * SleepTime}, LoadStage{4, 5 * SleepTime}, LoadStage{2, 5 * SleepTime})
+	stages := RunTestProfile(context.Background(), []ContextTask{tracker.task}, profile)
+
+	assertT.Equal(3, len(stages))
+	assertT.Equal(4, tracker.peak)
+	for i, stage := range stages {
+		assertT.Equal(profile[i].Concurrency, stage.Concurrency)
+		assertT.Equal(1, len(stage.Stats))
+		assertT.False(stage.Stats[0].Incomplete)
+		assertT.GreaterOrEqual(stage.Stats[0].Count, 3*stage.Concurrency)
+		assertT.LessOrEqual(stage.Stats[0].Count, 6*stage.Concurrency)
+	}
+	assertT.Less(stages[0].Start, SleepTime)
+	assertT.GreaterOrEqual(stages[2].Start, 10*SleepTime)
+}
+
+func TestRunTestProfileCancel(t *testing.T) {
+	assertT := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*SleepTime)
+	defer cancel()
+
+	profile := SteppedProfile(LoadStage{2, 3 * SleepTime}, LoadStage{2, time.Minute}, LoadStage{2, time.Minute})
+	stages := RunTestProfile(ctx, []ContextTask{sleepTask}, profile)
+
+	assertT.Equal(3, len(stages))
+	assertT.Greater(stages[0].Stats[0].Count, 0)
+	assertT.True(stages[1].Stats[0].Incomplete)
+	assertT.Equal(0, stages[2].Stats[0].Count)
+}
+
+func TestRunTestProfileWarmup(t *testing.T) {
+	assertT := assert.New(t)
+
+	profile := SteppedProfile(LoadStage{2, 3 * SleepTime}, LoadStage{4, 3 * SleepTime})
+	stages := RunTestProfile(context.Background(), []ContextTask{sleepTask}, profile, WithWarmupRuns(5))
+
+	assertT.Equal(5, len(stages[0].Stats[0].Warmup))
+	assertT.Equal(0, len(stages[1].Stats[0].Warmup))
+	assertT.Greater(stages[0].Stats[0].Count, 0)
+	assertT.Greater(stages[1].Stats[0].Count, 0)
+}
+
+func TestRunTestProfileWarmupFromZero(t *testing.T) {
+	assertT := assert.New(t)
+
+	profile := LinearRamp(0, 3, 40*time.Millisecond)
+	stages := RunTestProfile(context.Background(), []ContextTask{sleepTask}, profile, WithWarmupRuns(3))
+
+	assertT.Equal(4, len(stages))
+	assertT.Equal(3, len(stages[0].Stats[0].Warmup))
+	assertT.Equal(0, stages[0].Stats[0].Count)
+	assertT.Greater(stages[3].Stats[0].Count, 0)
+}
+
+func TestSetConcurrency(t *testing.T) {
+	assertT := assert.New(t)
+
+	run := newTestRun([]ContextTask{sleepTask}, 4, runConfig{})
+	defer run.finish(context.Background())
+	assertT.True(run.setConcurrency(context.Background(), 1))
+	assertT.Equal(3, run.parked)
+	assertT.Equal(3, len(run.sema))
+	assertT.True(run.setConcurrency(context.Background(), 3))
+	assertT.Equal(1, run.parked)
+	assertT.Equal(1, len(run.sema))
+}
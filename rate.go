@@ -0,0 +1,161 @@
+package perform
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Distribution of intervals between run starts in open-loop tests
+type ArrivalProcess int
+
+const (
+	// Runs are issued at constant intervals
+	ArrivalConstant ArrivalProcess = iota
+	// Intervals between runs are exponentially distributed (Poisson process)
+	ArrivalPoisson
+)
+
+// Parameters of open-loop test
+type RateConfig struct {
+	// Target arrival rate in runs per second (> 0)
+	Rate float64
+	// Distribution of inter-arrival intervals
+	Arrival ArrivalProcess
+	// Seed of random generator for Poisson arrivals
+	Seed int64
+	// Limit of concurrently executing runs; unlimited if <= 0 - then the number of in-flight runs
+	// is only bounded by the larger of total and warm-up runs
+	MaxInFlight int
+	// When in-flight limit is reached, drop the run instead of delaying it
+	DropOnLimit bool
+}
+
+// Runs tasks in the open-loop model - new runs are issued at the target rate regardless of
+// completion of previous runs. Execution time is measured from the intended start time,
+// so delays caused by saturation are included in latencies (no coordinated omission).
+//
+//   - ctx - context passed to tasks; its cancellation stops the test prematurely
+//
+//   - tasks - tasks to run
+//
+//   - totalRuns - total number of runs to issue
+//
+//   - config - arrival rate and in-flight limit parameters
+//
+//   - opts - optional parameters like warm-up
+//
+//     return time statistics for each task with counters of dropped and delayed runs
+func RunTestRate(ctx context.Context, tasks []ContextTask, totalRuns int, config RateConfig, opts ...RunOption) []RunStats {
+	if config.Rate <= 0 {
+		panic("arrival rate should be positive")
+	}
+	options := newRunConfig(opts)
+	maxInFlight := config.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = max(totalRuns, options.warmupRuns, 1)
+	}
+
+	run := newTestRun(tasks, maxInFlight, options)
+	run.unlimited = config.MaxInFlight <= 0
+	defer run.finish(ctx)
+	if err := run.setupSuite(ctx); err != nil {
+		stats := run.calcStats(run.fixtures)
+		markSetupFailure(stats, err)
+		return stats
+	}
+
+	schedule := func(ctx context.Context, stop <-chan time.Time, maxRuns int) int {
+		return run.schedule(ctx, stop, maxRuns, config)
+	}
+	run.warmUp(ctx, schedule)
+	run.beginMeasurement(totalRuns)
+	issued := schedule(ctx, nil, totalRuns)
+
+	stats := run.calcStats(run.fixtures)
+	if issued < totalRuns {
+		markIncomplete(stats)
+	}
+	return stats
+}
+
+// Issues runs at the configured rate until "maxRuns" are issued, the context is done or "stop" fires.
+// Waits for completion of started runs; returns number of issued runs including dropped ones.
+func (run *testRun) schedule(ctx context.Context, stop <-chan time.Time, maxRuns int, config RateConfig) int {
+	nextInterval := arrivalIntervals(config)
+
+	issued := 0
+	intended := time.Now()
+	for ; issued < maxRuns && run.sleepUntil(ctx, stop, intended); issued++ {
+		fixture := run.fixtures[run.selector()]
+		if run.acquireScheduledSlot(ctx, stop, fixture, config.DropOnLimit) {
+			run.submit(ctx, runJob{fixture: fixture, intended: intended})
+		} else if !config.DropOnLimit || ctx.Err() != nil || run.isAborted() {
+			break
+		}
+		intended = intended.Add(nextInterval())
+	}
+
+	run.waitGroup.Wait()
+	return issued
+}
+
+// Tries to take a slot immediately; otherwise either drops the run or waits for a slot until "stop" fires
+func (run *testRun) acquireScheduledSlot(ctx context.Context, stop <-chan time.Time, fixture *taskFixture, dropOnLimit bool) bool {
+	select {
+	case run.sema <- ND:
+		return true
+	default:
+	}
+
+	if dropOnLimit {
+		if !fixture.inWarmup {
+			fixture.dropped++
+		}
+		return false
+	}
+	if !fixture.inWarmup {
+		fixture.delayed++
+	}
+	return run.acquireSlot(ctx, stop)
+}
+
+func arrivalIntervals(config RateConfig) func() time.Duration {
+	period := float64(time.Second) / config.Rate
+	if config.Arrival == ArrivalPoisson {
+		rng := rand.New(rand.NewSource(config.Seed))
+		return func() time.Duration { return time.Duration(rng.ExpFloat64() * period) }
+	}
+	return func() time.Duration { return time.Duration(period) }
+}
+
+// Sleeps until the given time; returns false if the context is done, "stop" fires or the test is aborted first
+func (run *testRun) sleepUntil(ctx context.Context, stop <-chan time.Time, t time.Time) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-run.aborted:
+		return false
+	default:
+	}
+
+	wait := time.Until(t)
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	case <-run.aborted:
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+package perform
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	RateRuns = 20
+	HighRate = 1000.0
+)
+
+func sleepTask(context.Context) error {
+	time.Sleep(SleepTime)
+	return nil
+}
+
+func TestRunTestRate(t *testing.T) {
+	assertT := assert.New(t)
+
+	startTime := time.Now()
+	stats := RunTestRate(context.Background(), []ContextTask{sleepTask}, RateRuns, RateConfig{Rate: 200})
+	elapsedTime := time.Since(startTime)
+
+	assertT.Equal(1, len(stats))
+	assertT.Equal(RateRuns, stats[0].Count)
+	assertT.Equal(0, stats[0].Dropped)
+	assertT.Equal(0, stats[0].Delayed)
+	assertT.False(stats[0].Incomplete)
+	assertT.GreaterOrEqual(elapsedTime, (RateRuns-1)*5*time.Millisecond)
+}
+
+func TestRunTestRateDrop(t *testing.T) {
+	assertT := assert.New(t)
+
+	config := RateConfig{Rate: HighRate, MaxInFlight: 1, DropOnLimit: true}
+	stats := RunTestRate(context.Background(), []ContextTask{sleepTask}, RateRuns, config)
+
+	assertT.Equal(RateRuns, stats[0].Count+stats[0].Dropped)
+	assertT.Greater(stats[0].Dropped, 0)
+	assertT.Equal(0, stats[0].Delayed)
+	assertT.Greater(stats[0].OfferedLoad, stats[0].Throughput)
+}
+
+func TestRunTestRateDelay(t *testing.T) {
+	assertT := assert.New(t)
+
+	config := RateConfig{Rate: HighRate, MaxInFlight: 1}
+	stats := RunTestRate(context.Background(), []ContextTask{sleepTask}, RateRuns, config)
+
+	assertT.Equal(RateRuns, stats[0].Count)
+	assertT.Equal(0, stats[0].Dropped)
+	assertT.Greater(stats[0].Delayed, 0)
+	// latency of the last run includes waiting in the queue
+	assertT.Greater(stats[0].MaxTime, float64(RateRuns/2*SleepTime)/msecFctr)
+}
+
+func TestRunTestRateWorkerSetup(t *testing.T) {
+	assertT := assert.New(t)
+
+	hooks := Hooks[int]{SetupWorker: func(context.Context, int) (int, error) {
+		time.Sleep(5 * SleepTime)
+		return 0, nil
+	}}
+	noop := func(context.Context) error { return nil }
+	stats := RunTestRate(context.Background(), []ContextTask{noop}, 5, RateConfig{Rate: 10, MaxInFlight: 1}, WithHooks(hooks))
+
+	assertT.Equal(5, stats[0].Count)
+	// worker setup is not counted in latency
+	assertT.Less(stats[0].MaxTime, float64(SleepTime)/msecFctr)
+}
+
+func TestRunTestRateUnlimited(t *testing.T) {
+	assertT := assert.New(t)
+
+	var report RunReport
+	stats := RunTestRate(context.Background(), []ContextTask{sleepTask}, 0, RateConfig{Rate: HighRate},
+		WithWarmupRuns(5), WithReport(&report))
+
+	assertT.Equal(5, len(stats[0].Warmup))
+	assertT.Equal(0, stats[0].Count)
+	assertT.Equal(0, stats[0].Delayed)
+	assertT.Equal(0, report.Concurrency)
+}
+
+func TestRunTestRateCancel(t *testing.T) {
+	assertT := assert.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*SleepTime)
+	defer cancel()
+
+	stats := RunTestRate(ctx, []ContextTask{sleepTask}, RateRuns, RateConfig{Rate: 100})
+
+	assertT.Less(stats[0].Count, RateRuns)
+	assertT.True(stats[0].Incomplete)
+}
+
+func TestArrivalIntervals(t *testing.T) {
+	assertT := assert.New(t)
+
+	constant := arrivalIntervals(RateConfig{Rate: HighRate})
+	assertT.Equal(time.Millisecond, constant())
+	assertT.Equal(time.Millisecond, constant())
+
+	poisson := arrivalIntervals(RateConfig{Rate: HighRate, Arrival: ArrivalPoisson, Seed: 1})
+	var total time.Duration
+	for range 10000 {
+		total += poisson()
+	}
+	assertT.InDelta(float64(time.Millisecond), float64(total/10000), 0.05*float64(time.Millisecond))
+
+	assertT.Panics(func() { RunTestRate(context.Background(), []ContextTask{sleepTask}, 1, RateConfig{}) })
+}
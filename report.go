@@ -0,0 +1,122 @@
+package perform
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Run-level summary of a test - complements per-task statistics
+type RunReport struct {
+	// Start of the measured phase
+	Start time.Time `json,yaml:"start"`
+	// End of the measured phase including completion of in-flight runs
+	End time.Time `json,yaml:"end"`
+	// Duration of the measured phase
+	WallTime time.Duration `json,yaml:"wall_time"`
+	// Number of completed measured runs of all tasks
+	Runs int `json,yaml:"runs"`
+	// Completed runs per second
+	Throughput float64 `json,yaml:"throughput"`
+	// Completed runs per second for each task - same as `RunStats.Throughput` except for load profiles
+	TaskThroughput []float64 `json,yaml:"task_throughput"`
+	// Configured limit of concurrent runs; 0 if unlimited
+	Concurrency int `json,yaml:"concurrency"`
+	// Highest number of concurrent runs actually reached
+	MaxConcurrency int `json,yaml:"max_concurrency"`
+	// Environment of the test
+	Env Environment `json,yaml:"env"`
+}
+
+// Description of the environment where the test was run
+type Environment struct {
+	GoMaxProcs  int    `json,yaml:"gomaxprocs"`
+	NumCPU      int    `json,yaml:"num_cpu"`
+	GoVersion   string `json,yaml:"go_version"`
+	OS          string `json,yaml:"os"`
+	Arch        string `json,yaml:"arch"`
+	Hostname    string `json,yaml:"hostname"`
+	GitRevision string `json,yaml:"git_revision"` // empty if the binary has no VCS stamping
+}
+
+// Fills the report when the test function returns
+func WithReport(report *RunReport) RunOption {
+	return func(c *runConfig) { c.report = report }
+}
+
+// Collects description of the current environment. Git revision is taken from the build information only;
+// it is empty for binaries built without VCS stamping (e.g. `go test`) - callers may fill it in themselves.
+func CurrentEnvironment() Environment {
+	env := Environment{
+		GoMaxProcs:  runtime.GOMAXPROCS(0),
+		NumCPU:      runtime.NumCPU(),
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GitRevision: gitRevision(),
+	}
+	env.Hostname, _ = os.Hostname()
+	return env
+}
+
+func gitRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return ""
+}
+
+// Updates the highest number of concurrent runs; should be called by dispatcher after acquiring a slot
+func (run *testRun) trackConcurrency() {
+	if !run.startTime.IsZero() {
+		run.maxInFlight = max(run.maxInFlight, len(run.sema)-run.parked)
+	}
+}
+
+// Fills the report requested with `WithReport`
+func (run *testRun) fillReport() {
+	report := run.config.report
+	if report == nil {
+		return
+	}
+
+	wallTime := run.measuredTime()
+	*report = RunReport{Start: run.startTime, End: run.startTime.Add(wallTime), WallTime: wallTime,
+		Concurrency: cap(run.sema), MaxConcurrency: run.maxInFlight, Env: CurrentEnvironment()}
+	if run.unlimited {
+		report.Concurrency = 0
+	}
+	if run.startTime.IsZero() {
+		report.Start = time.Now()
+		report.End = report.Start
+	}
+
+	taskRuns := make([]int, len(run.fixtures))
+	for _, fixtures := range run.fixtureSets {
+		for i, fixture := range fixtures {
+			taskRuns[i] += fixture.measuredRuns()
+		}
+	}
+	report.TaskThroughput = make([]float64, len(taskRuns))
+	for i, runs := range taskRuns {
+		report.Runs += runs
+		report.TaskThroughput[i] = perSecond(runs, wallTime)
+	}
+	report.Throughput = perSecond(report.Runs, wallTime)
+}
+
+// Number of recorded runs excluding warm-up ones
+func (fixture *taskFixture) measuredRuns() int {
+	fixture.lock.Lock()
+	defer fixture.lock.Unlock()
+	runs := len(fixture.runtimes) + len(fixture.failTimes)
+	if fixture.hist != nil {
+		runs += fixture.hist.Count()
+	}
+	return runs
+}
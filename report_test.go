@@ -0,0 +1,71 @@
+package perform
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReport(t *testing.T) {
+	assertT := assert.New(t)
+
+	var report RunReport
+	startTime := time.Now()
+	stats := RunTestContext(context.Background(), []ContextTask{sleepTask, sleepTask}, TotalTests, Parallel,
+		WithReport(&report), WithWarmupRuns(Parallel))
+	elapsedTime := time.Since(startTime)
+
+	assertT.True(report.Start.After(startTime))
+	assertT.True(report.End.After(report.Start))
+	assertT.Equal(report.End.Sub(report.Start), report.WallTime)
+	assertT.Less(report.WallTime, elapsedTime)
+	assertT.GreaterOrEqual(report.WallTime, TotalTests/Parallel*SleepTime)
+
+	assertT.Equal(TotalTests, report.Runs)
+	assertT.InDelta(float64(TotalTests)/report.WallTime.Seconds(), report.Throughput, 1e-6)
+	assertT.Equal(2, len(report.TaskThroughput))
+	assertT.InDelta(report.Throughput, report.TaskThroughput[0]+report.TaskThroughput[1], 1e-6)
+	assertT.Equal(stats[0].Throughput, report.TaskThroughput[0])
+	assertT.Equal(stats[1].Throughput, report.TaskThroughput[1])
+
+	assertT.Equal(Parallel, report.Concurrency)
+	assertT.Equal(Parallel, report.MaxConcurrency)
+
+	assertT.Equal(runtime.NumCPU(), report.Env.NumCPU)
+	assertT.Equal(runtime.GOMAXPROCS(0), report.Env.GoMaxProcs)
+	assertT.Equal(runtime.Version(), report.Env.GoVersion)
+}
+
+func TestRunReportProfile(t *testing.T) {
+	assertT := assert.New(t)
+
+	var report RunReport
+	profile := SteppedProfile(LoadStage{Concurrency: 2, Duration: 5 * SleepTime},
+		LoadStage{Concurrency: 4, Duration: 5 * SleepTime})
+	stages := RunTestProfile(context.Background(), []ContextTask{sleepTask}, profile, WithReport(&report))
+
+	runs := 0
+	for _, stage := range stages {
+		runs += stage.Stats[0].Count
+	}
+	assertT.Equal(runs, report.Runs)
+	assertT.Equal(4, report.Concurrency)
+	assertT.Equal(4, report.MaxConcurrency)
+}
+
+func TestRunReportSetupFailure(t *testing.T) {
+	assertT := assert.New(t)
+
+	var report RunReport
+	hooks := Hooks[int]{SetupSuite: func(context.Context) error { return errTest }}
+	RunTestContext(context.Background(), []ContextTask{sleepTask}, TotalTests, Parallel, WithHooks(hooks),
+		WithReport(&report))
+
+	assertT.Equal(0, report.Runs)
+	assertT.Equal(time.Duration(0), report.WallTime)
+	assertT.Equal(0.0, report.Throughput)
+	assertT.Equal(0, report.MaxConcurrency)
+}
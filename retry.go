@@ -0,0 +1,120 @@
+package perform
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Policy of retrying failed runs. The task is called again in the same slot; latency of the run includes
+// all attempts and pauses between them, so retries don't hide latency regressions.
+type RetryPolicy struct {
+	// Maximal number of retries after the first attempt
+	MaxRetries int
+	// Pause before the first retry
+	Backoff time.Duration
+	// Growth factor of pauses between retries; pauses are constant if <= 1
+	Multiplier float64
+	// Limit of pauses; unlimited if <= 0
+	MaxBackoff time.Duration
+	// Selects errors to retry; all errors are retried if nil. Feeder exhaustion and errors after
+	// cancellation of the test are never retried.
+	Retryable func(err error) bool
+}
+
+// Retries failed runs according to the policy. Statistics of first attempts are reported in `RunStats.FirstAttempt`
+// and number of retries in `RunStats.Retries`. Feeder tasks receive the same record in all attempts.
+func WithRetries(policy RetryPolicy) RunOption {
+	if policy.MaxRetries < 0 || policy.Backoff < 0 {
+		panic("number of retries and backoff should not be negative")
+	}
+	return func(c *runConfig) { c.retry = &policy }
+}
+
+// Outcome of the first attempt of a run and number of retries
+type attempts struct {
+	firstEnd   time.Time
+	firstErr   error
+	retries    int
+	begin      time.Time // start of the first attempt - set with tick clock only
+	firstTicks uint64    // duration of the first attempt in ticks
+	ticks      uint64    // duration of all attempts in ticks
+}
+
+// Calls the task retrying failed attempts according to the retry policy
+func (fixture *taskFixture) executeWithRetries(ctx context.Context) (attempts, error) {
+	var ret attempts
+	ticks := fixture.config.ticks
+	if ticks != nil {
+		ret.begin = time.Now()
+	}
+	begin := ticks.read()
+	err := fixture.execute(ctx)
+	ret.firstTicks = ticks.read() - begin
+	ret.ticks = ret.firstTicks
+	ret.firstEnd = time.Now()
+	ret.firstErr = err
+
+	policy := fixture.config.retry
+	if policy == nil {
+		return ret, err
+	}
+
+	backoff := policy.Backoff
+	for ; ret.retries < policy.MaxRetries && policy.shouldRetry(ctx, err); ret.retries++ {
+		if !sleepCtx(ctx, backoff) {
+			break
+		}
+		backoff = policy.nextBackoff(backoff)
+		err = fixture.execute(ctx)
+	}
+	ret.ticks = ticks.read() - begin
+	return ret, err
+}
+
+func (policy *RetryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ErrFeederExhausted) {
+		return false
+	}
+	return policy.Retryable == nil || policy.Retryable(err)
+}
+
+func (policy *RetryPolicy) nextBackoff(backoff time.Duration) time.Duration {
+	if policy.Multiplier > 1 {
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+	}
+	if policy.MaxBackoff > 0 {
+		backoff = min(backoff, policy.MaxBackoff)
+	}
+	return backoff
+}
+
+// Sleeps for the given period; returns false if the context is done first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Adds first attempt results and retry counters; should be called under lock
+func (fixture *taskFixture) storeAttempts(start time.Time, firstTime time.Duration, a attempts) {
+	if fixture.config.retry == nil {
+		return
+	}
+	if fixture.first == nil {
+		fixture.first = fixture.childFixture()
+	}
+	fixture.first.store(start, firstTime, a.firstErr)
+	if a.retries > 0 {
+		fixture.retried++
+		fixture.retries += a.retries
+	}
+}
@@ -0,0 +1,124 @@
+package perform
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const RetryRuns = 20
+
+func TestRetries(t *testing.T) {
+	assertT := assert.New(t)
+
+	var calls atomic.Int32
+	flaky := func(context.Context) error {
+		if calls.Add(1)%2 == 1 {
+			return errTest
+		}
+		return nil
+	}
+	policy := RetryPolicy{MaxRetries: 3, Backoff: SleepTime}
+	stats := RunTestContext(context.Background(), []ContextTask{flaky}, RetryRuns, 1, WithRetries(policy))
+
+	oneStat := stats[0]
+	assertT.Equal(RetryRuns, oneStat.Count)
+	assertT.Equal(0, oneStat.Fails)
+	assertT.Equal(RetryRuns, oneStat.Retries)
+	assertT.Equal(RetryRuns, oneStat.RetriedRuns)
+	assertT.GreaterOrEqual(oneStat.MinTime, float64(SleepTime)/msecFctr)
+
+	first := oneStat.FirstAttempt
+	assertT.NotNil(first)
+	assertT.Equal(RetryRuns, first.Count)
+	assertT.Equal(RetryRuns, first.Fails)
+	assertT.Less(first.MaxTime, float64(SleepTime)/msecFctr)
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	assertT := assert.New(t)
+
+	failing := func(context.Context) error { return errTest }
+	stats := RunTestContext(context.Background(), []ContextTask{failing}, RetryRuns, Parallel,
+		WithRetries(RetryPolicy{MaxRetries: 2}))
+
+	assertT.Equal(RetryRuns, stats[0].Fails)
+	assertT.Equal(2*RetryRuns, stats[0].Retries)
+	assertT.Equal(RetryRuns, stats[0].RetriedRuns)
+	assertT.Equal(RetryRuns, stats[0].FirstAttempt.Fails)
+}
+
+func TestRetriesNotRetryable(t *testing.T) {
+	assertT := assert.New(t)
+
+	var calls atomic.Int32
+	failing := func(context.Context) error {
+		calls.Add(1)
+		return errTest
+	}
+	policy := RetryPolicy{MaxRetries: 2, Retryable: func(err error) bool { return !errors.Is(err, errTest) }}
+	stats := RunTestContext(context.Background(), []ContextTask{failing}, RetryRuns, Parallel, WithRetries(policy))
+
+	assertT.Equal(RetryRuns, int(calls.Load()))
+	assertT.Equal(RetryRuns, stats[0].Fails)
+	assertT.Equal(0, stats[0].Retries)
+	assertT.Equal(0, stats[0].RetriedRuns)
+}
+
+func TestRetriesDisabled(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats := RunTestContext(context.Background(), []ContextTask{sleepTask}, RetryRuns, Parallel)
+
+	assertT.Nil(stats[0].FirstAttempt)
+	assertT.Equal(0, stats[0].Retries)
+}
+
+func TestRetriesFeeder(t *testing.T) {
+	assertT := assert.New(t)
+
+	records := make([]int, RetryRuns)
+	for i := range records {
+		records[i] = i
+	}
+	var lock sync.Mutex
+	seen := make(map[int]int)
+	task := func(_ context.Context, record int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		seen[record]++
+		if seen[record] == 1 {
+			return errTest
+		}
+		return nil
+	}
+	feeder := NewSliceFeeder(records, FeedSequential, 0)
+	stats := RunTestContext(context.Background(), []ContextTask{WithFeeder(feeder, task, nil)}, 2*RetryRuns, Parallel,
+		WithRetries(RetryPolicy{MaxRetries: 1}))
+
+	assertT.Equal(RetryRuns, stats[0].Count)
+	assertT.Equal(0, stats[0].Fails)
+	assertT.Equal(RetryRuns, stats[0].Retries)
+	for _, record := range records {
+		assertT.Equal(2, seen[record])
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	assertT := assert.New(t)
+
+	policy := RetryPolicy{Backoff: time.Millisecond, Multiplier: 2, MaxBackoff: 5 * time.Millisecond}
+	assertT.Equal(2*time.Millisecond, policy.nextBackoff(time.Millisecond))
+	assertT.Equal(4*time.Millisecond, policy.nextBackoff(2*time.Millisecond))
+	assertT.Equal(5*time.Millisecond, policy.nextBackoff(4*time.Millisecond))
+
+	constant := RetryPolicy{Backoff: time.Millisecond}
+	assertT.Equal(time.Millisecond, constant.nextBackoff(time.Millisecond))
+
+	assertT.Panics(func() { WithRetries(RetryPolicy{MaxRetries: -1}) })
+}
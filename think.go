@@ -0,0 +1,96 @@
+package perform
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Distribution of pauses between consecutive runs of a worker
+type ThinkTime func(rng *rand.Rand) time.Duration
+
+// Pauses of constant duration
+func ConstantThink(d time.Duration) ThinkTime {
+	return func(*rand.Rand) time.Duration { return d }
+}
+
+// Pauses uniformly distributed in range [lo, hi]
+func UniformThink(lo, hi time.Duration) ThinkTime {
+	if lo < 0 || hi < lo {
+		panic("invalid think time range")
+	}
+	return func(rng *rand.Rand) time.Duration { return lo + time.Duration(rng.Int63n(int64(hi-lo)+1)) }
+}
+
+// Exponentially distributed pauses with the given mean
+func ExponentialThink(mean time.Duration) ThinkTime {
+	return func(rng *rand.Rand) time.Duration { return time.Duration(rng.ExpFloat64() * float64(mean)) }
+}
+
+// Normally distributed pauses; negative values are replaced with zero
+func GaussianThink(mean, stdDev time.Duration) ThinkTime {
+	return func(rng *rand.Rand) time.Duration {
+		return max(time.Duration(rng.NormFloat64()*float64(stdDev))+mean, 0)
+	}
+}
+
+// Sets pauses made by workers after each run, as real clients do between requests. Workers keep their slots
+// while pausing, so the pauses lower throughput. "seed" makes random pauses reproducible.
+// Think time is not included in latencies.
+func WithThinkTime(think ThinkTime, seed int64) RunOption {
+	return func(c *runConfig) {
+		c.think = think
+		c.thinkSeed = seed
+	}
+}
+
+// Sets minimum interval between starts of consecutive runs of each worker
+func WithPacing(interval time.Duration) RunOption {
+	return func(c *runConfig) { c.pacing = interval }
+}
+
+// Pauses the worker after a run started at "start" according to think time and pacing settings
+func (fixture *taskFixture) pause(ctx context.Context, start time.Time) {
+	config := fixture.config
+	if config.think == nil && config.pacing <= 0 {
+		return
+	}
+	wc, ok := ctx.Value(workerContextKey{}).(*workerContext)
+	if !ok {
+		return
+	}
+
+	var wait time.Duration
+	if config.think != nil {
+		wait = config.think(wc.rng)
+	}
+	if config.pacing > 0 {
+		wait = max(wait, time.Until(start.Add(config.pacing)))
+	}
+	if wait <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Sets numbers of completed and issued runs per second during the measured phase of the given duration
+func setThroughput(stats []RunStats, elapsed time.Duration) {
+	for i := range stats {
+		runs := stats[i].Count + len(stats[i].FailValues)
+		stats[i].Throughput = perSecond(runs, elapsed)
+		stats[i].OfferedLoad = perSecond(runs+stats[i].Dropped, elapsed)
+	}
+}
+
+func perSecond(runs int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(runs) / elapsed.Seconds()
+}
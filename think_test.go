@@ -0,0 +1,84 @@
+package perform
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const PauseRuns = 20
+
+func sampleThink(think ThinkTime, n int) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	ret := make([]float64, n)
+	for i := range ret {
+		ret[i] = float64(think(rng))
+	}
+	return ret
+}
+
+func TestThinkTimeDistributions(t *testing.T) {
+	assertT := assert.New(t)
+
+	ms := float64(time.Millisecond)
+
+	constant := sampleThink(ConstantThink(time.Millisecond), 10)
+	assertT.Equal(ms, mean(constant))
+	assertT.Equal(0.0, variance(constant))
+
+	uniform := sampleThink(UniformThink(time.Millisecond, 3*time.Millisecond), 10000)
+	assertT.InDelta(2*ms, mean(uniform), 0.05*ms)
+	for _, v := range uniform {
+		assertT.GreaterOrEqual(v, ms)
+		assertT.LessOrEqual(v, 3*ms)
+	}
+	assertT.Panics(func() { UniformThink(3*time.Millisecond, time.Millisecond) })
+	assertT.Panics(func() { UniformThink(-time.Millisecond, time.Millisecond) })
+
+	exponential := sampleThink(ExponentialThink(time.Millisecond), 10000)
+	assertT.InDelta(ms, mean(exponential), 0.05*ms)
+
+	gaussian := sampleThink(GaussianThink(10*time.Millisecond, time.Millisecond), 10000)
+	assertT.InDelta(10*ms, mean(gaussian), 0.05*ms)
+
+	clamped := sampleThink(GaussianThink(0, time.Millisecond), 1000)
+	for _, v := range clamped {
+		assertT.GreaterOrEqual(v, 0.0)
+	}
+}
+
+func TestRunTestThinkTime(t *testing.T) {
+	assertT := assert.New(t)
+
+	startTime := time.Now()
+	stats := RunTest([]TestTask{func() error { return nil }}, PauseRuns, 1, WithThinkTime(ConstantThink(SleepTime/2), 0))
+	elapsedTime := time.Since(startTime)
+
+	assertT.Equal(PauseRuns, stats[0].Count)
+	assertT.GreaterOrEqual(elapsedTime, PauseRuns*SleepTime/2)
+	assertT.Less(stats[0].MaxTime, float64(SleepTime/2)/msecFctr)
+	// runs start at least a pause apart; the pause after the last run is not measured
+	assertT.LessOrEqual(stats[0].Throughput, PauseRuns/((PauseRuns-1)*SleepTime/2).Seconds())
+	assertT.Equal(stats[0].Throughput, stats[0].OfferedLoad)
+}
+
+func TestRunTestPacing(t *testing.T) {
+	assertT := assert.New(t)
+
+	task := func() error {
+		time.Sleep(SleepTime / 2)
+		return nil
+	}
+
+	startTime := time.Now()
+	stats := RunTest([]TestTask{task}, PauseRuns, 2, WithPacing(SleepTime))
+	elapsedTime := time.Since(startTime)
+
+	assertT.Equal(PauseRuns, stats[0].Count)
+	assertT.GreaterOrEqual(elapsedTime, (PauseRuns/2-1)*SleepTime)
+	assertT.Less(stats[0].MaxTime, float64(SleepTime)/msecFctr)
+	assertT.Greater(stats[0].Throughput, 0.0)
+	assertT.LessOrEqual(stats[0].Throughput, PauseRuns/((PauseRuns/2-1)*SleepTime).Seconds())
+}
@@ -0,0 +1,68 @@
+package perform
+
+import (
+	"sort"
+	"time"
+)
+
+// Point of throughput and latency time series - time values in milliseconds
+type TimePoint struct {
+	Time       time.Time `json,yaml:"time"`       // start of the interval
+	Count      int       `json,yaml:"count"`      // runs started within the interval
+	Throughput float64   `json,yaml:"throughput"` // runs per second
+	AvgTime    float64   `json,yaml:"avg_time"`
+	MedTime    float64   `json,yaml:"med_time"`
+	MaxTime    float64   `json,yaml:"max_time"`
+}
+
+// Buckets runs of one or several tasks by their start time into intervals of the given length
+// counted from "origin". If origin is zero, the start of the earliest run is used; runs started
+// before origin are skipped. Intervals without runs are included with zero values.
+func TimeSeries(origin time.Time, interval time.Duration, stats ...RunStats) []TimePoint {
+	if interval <= 0 {
+		panic("time series interval should be positive")
+	}
+
+	type startedRun struct {
+		start    time.Time
+		duration float64
+	}
+
+	runs := make([]startedRun, 0)
+	for _, rs := range stats {
+		for i, offset := range rs.Starts {
+			start := rs.StartTime.Add(time.Duration(offset * msecFctr))
+			runs = append(runs, startedRun{start: start, duration: rs.Values[i]})
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].start.Before(runs[j].start) })
+	if origin.IsZero() && len(runs) > 0 {
+		origin = runs[0].start
+	}
+	skipped := sort.Search(len(runs), func(i int) bool { return !runs[i].start.Before(origin) })
+	runs = runs[skipped:]
+	if len(runs) == 0 {
+		return []TimePoint{}
+	}
+
+	numPoints := int(runs[len(runs)-1].start.Sub(origin)/interval) + 1
+	buckets := make([][]float64, numPoints)
+	for _, r := range runs {
+		idx := int(r.start.Sub(origin) / interval)
+		buckets[idx] = append(buckets[idx], r.duration)
+	}
+
+	ret := make([]TimePoint, numPoints)
+	for i, durations := range buckets {
+		ret[i].Time = origin.Add(time.Duration(i) * interval)
+		ret[i].Count = len(durations)
+		ret[i].Throughput = float64(len(durations)) / interval.Seconds()
+		if len(durations) > 0 {
+			sort.Float64s(durations)
+			ret[i].AvgTime = mean(durations)
+			ret[i].MedTime = percentile(durations, 50)
+			ret[i].MaxTime = durations[len(durations)-1]
+		}
+	}
+	return ret
+}
@@ -0,0 +1,75 @@
+package perform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeSeries(t *testing.T) {
+	assertT := assert.New(t)
+
+	origin := time.Date(2025, 1, 1, 10, 0, 0, 500_000_000, time.UTC)
+	stats1 := RunStats{StartTime: origin, Starts: []float64{0, 100, 600}, Values: []float64{1, 3, 5}}
+	stats2 := RunStats{StartTime: origin, Starts: []float64{200, 2600}, Values: []float64{2, 7}}
+
+	points := TimeSeries(time.Time{}, time.Second, stats1, stats2)
+
+	assertT.Equal(3, len(points))
+	assertT.Equal(origin, points[0].Time)
+	assertT.Equal(4, points[0].Count)
+	assertT.Equal(4.0, points[0].Throughput)
+	assertT.Equal(2.75, points[0].AvgTime)
+	assertT.Equal(2.5, points[0].MedTime)
+	assertT.Equal(5.0, points[0].MaxTime)
+
+	assertT.Equal(origin.Add(time.Second), points[1].Time)
+	assertT.Equal(0, points[1].Count)
+	assertT.Equal(0.0, points[1].Throughput)
+
+	assertT.Equal(1, points[2].Count)
+	assertT.Equal(7.0, points[2].AvgTime)
+
+	assertT.Empty(TimeSeries(time.Time{}, time.Second, RunStats{}))
+	assertT.Panics(func() { TimeSeries(time.Time{}, 0, stats1) })
+}
+
+func TestTimeSeriesOrigin(t *testing.T) {
+	assertT := assert.New(t)
+
+	origin := time.Date(2025, 1, 1, 10, 0, 0, 500_000_000, time.UTC)
+	stats := RunStats{StartTime: origin, Starts: []float64{0, 100, 600}, Values: []float64{1, 3, 5}}
+
+	points := TimeSeries(origin.Add(-500*time.Millisecond), time.Second, stats)
+	assertT.Equal(2, len(points))
+	assertT.Equal(origin.Add(-500*time.Millisecond), points[0].Time)
+	assertT.Equal(2, points[0].Count)
+	assertT.Equal(1, points[1].Count)
+
+	points = TimeSeries(origin.Add(50*time.Millisecond), time.Second, stats)
+	assertT.Equal(1, len(points))
+	assertT.Equal(2, points[0].Count)
+
+	assertT.Empty(TimeSeries(origin.Add(time.Second), time.Second, stats))
+}
+
+func TestRunStarts(t *testing.T) {
+	assertT := assert.New(t)
+
+	stats := RunTest([]TestTask{func() error { return nil }}, TotalTests, 1)
+
+	oneStat := stats[0]
+	assertT.Equal(TotalTests, len(oneStat.Starts))
+	assertT.False(oneStat.StartTime.IsZero())
+	for i := 1; i < len(oneStat.Starts); i++ {
+		assertT.GreaterOrEqual(oneStat.Starts[i], oneStat.Starts[i-1])
+	}
+
+	points := TimeSeries(oneStat.StartTime, time.Second, oneStat)
+	total := 0
+	for _, p := range points {
+		total += p.Count
+	}
+	assertT.Equal(TotalTests, total)
+}
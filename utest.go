@@ -0,0 +1,168 @@
+package perform
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// A MannWhitneyUTestResult is the result of a Mann-Whitney U-test.
+type MannWhitneyUTestResult struct {
+	// N1 and N2 are the sizes of the input samples.
+	N1, N2 int
+
+	// U is the value of the Mann-Whitney U statistic for this
+	// test, generalized by counting ties as 0.5.
+	U float64
+
+	// AltHypothesis specifies the alternative hypothesis tested
+	// by this test against the null hypothesis that there is no
+	// difference in the locations of the samples.
+	AltHypothesis LocationHypothesis
+
+	// Exact is set when the p-value is calculated from the exact
+	// distribution of U rather than from its normal approximation.
+	Exact bool
+
+	// P is the p-value of the Mann-Whitney test for the given
+	// null hypothesis.
+	P float64
+}
+
+// MannWhitneyExactLimit gives the largest sample size for which the
+// exact U distribution is used to compute the p-value of samples
+// without ties. Larger samples or samples with ties use the normal
+// approximation with tie correction.
+var MannWhitneyExactLimit = 50
+
+// ErrSamplesEqual is returned when all values of both samples are equal.
+var ErrSamplesEqual = errors.New("all samples are equal")
+
+// MannWhitneyUTest performs a Mann-Whitney U-test (also known as
+// Wilcoxon rank-sum test) of the null hypothesis that x1 and x2 come
+// from the same population against the alternative hypothesis that
+// one sample tends to have larger or smaller values than the other.
+//
+// This is a non-parametric test - unlike t-test it doesn't assume
+// normal distribution of samples, which makes it suitable for skewed
+// and multi-modal latencies. LocationLess is the alternative
+// hypothesis that values of x1 tend to be smaller than values of x2.
+//
+// For small samples without ties (see MannWhitneyExactLimit) the
+// p-value is exact. Otherwise it is calculated with the normal
+// approximation of U with tie and continuity corrections.
+func MannWhitneyUTest(x1, x2 []float64, alt LocationHypothesis) (*MannWhitneyUTestResult, error) {
+	n1, n2 := len(x1), len(x2)
+	if n1 == 0 || n2 == 0 {
+		return nil, ErrSampleSize
+	}
+
+	// Rank the merged samples; ties get average rank
+	merged := make([]float64, 0, n1+n2)
+	merged = append(merged, x1...)
+	merged = append(merged, x2...)
+	sort.Float64s(merged)
+	if merged[0] == merged[len(merged)-1] {
+		return nil, ErrSamplesEqual
+	}
+
+	r1 := 0.0
+	for _, x := range x1 {
+		lo := sort.SearchFloat64s(merged, x)
+		hi := sort.Search(len(merged), func(i int) bool { return merged[i] > x })
+		r1 += float64(lo+hi+1) / 2
+	}
+	u1 := r1 - float64(n1*(n1+1))/2
+
+	// Tie correction term - sum of t³-t over groups of t equal values
+	tieSum := 0.0
+	for i := 0; i < len(merged); {
+		j := i + 1
+		for j < len(merged) && merged[j] == merged[i] {
+			j++
+		}
+		t := float64(j - i)
+		tieSum += t*t*t - t
+		i = j
+	}
+
+	res := &MannWhitneyUTestResult{N1: n1, N2: n2, U: u1, AltHypothesis: alt}
+	if tieSum == 0 && n1 <= MannWhitneyExactLimit && n2 <= MannWhitneyExactLimit {
+		res.Exact = true
+		res.P = uExactP(int(u1), n1, n2, alt)
+		return res, nil
+	}
+
+	n := float64(n1 + n2)
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * (n + 1 - tieSum/(n*(n-1))))
+	res.P = uNormalP(u1, mu, sigma, alt)
+	return res, nil
+}
+
+// P-value of U statistic from its exact distribution
+func uExactP(u, n1, n2 int, alt LocationHypothesis) float64 {
+	cdf := uDistCdf(n1, n2)
+	less := cdf[u]
+	greater := 1.0
+	if u > 0 {
+		greater = 1 - cdf[u-1]
+	}
+	switch alt {
+	case LocationLess:
+		return less
+	case LocationGreater:
+		return greater
+	default:
+		return min(2*min(less, greater), 1)
+	}
+}
+
+// Cumulative distribution of U statistic for samples of sizes n1 and n2 without ties.
+// Number of rank arrangements with the given U is the coefficient of the Gaussian binomial
+// coefficient [n1+n2 choose n1] polynomial, which is built as product of (1-q^(n1+i))/(1-q^i).
+func uDistCdf(n1, n2 int) []float64 {
+	size := n1*n2 + 1
+	counts := make([]float64, size)
+	counts[0] = 1
+	for i := 1; i <= n2; i++ {
+		// Multiply by (1 - q^(n1+i))
+		for k := size - 1; k >= n1+i; k-- {
+			counts[k] -= counts[k-n1-i]
+		}
+		// Divide by (1 - q^i)
+		for k := i; k < size; k++ {
+			counts[k] += counts[k-i]
+		}
+	}
+
+	total := 0.0
+	for _, c := range counts {
+		total += c
+	}
+	cdf := make([]float64, size)
+	cum := 0.0
+	for k, c := range counts {
+		cum += c
+		cdf[k] = min(cum/total, 1)
+	}
+	return cdf
+}
+
+// P-value of U statistic under normal approximation with continuity correction
+func uNormalP(u, mu, sigma float64, alt LocationHypothesis) float64 {
+	less := normalCdf((u - mu + 0.5) / sigma)
+	greater := 1 - normalCdf((u-mu-0.5)/sigma)
+	switch alt {
+	case LocationLess:
+		return less
+	case LocationGreater:
+		return greater
+	default:
+		return min(2*min(less, greater), 1)
+	}
+}
+
+func normalCdf(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
@@ -0,0 +1,76 @@
+package perform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMannWhitneyUTestExact(t *testing.T) {
+	assertT := assert.New(t)
+
+	x1 := []float64{1, 2, 3, 4, 5}
+	x2 := []float64{6, 7, 8, 9, 10}
+
+	res, err := MannWhitneyUTest(x1, x2, LocationLess)
+	assertT.NoError(err)
+	assertT.Equal(5, res.N1)
+	assertT.Equal(5, res.N2)
+	assertT.Equal(0.0, res.U)
+	assertT.True(res.Exact)
+	assertT.InDelta(1.0/252, res.P, 1e-12)
+
+	res, err = MannWhitneyUTest(x1, x2, LocationGreater)
+	assertT.NoError(err)
+	assertT.InDelta(1.0, res.P, 1e-12)
+
+	res, err = MannWhitneyUTest(x2, x1, LocationDiffers)
+	assertT.NoError(err)
+	assertT.Equal(25.0, res.U)
+	assertT.InDelta(2.0/252, res.P, 1e-12)
+}
+
+func TestMannWhitneyUTestTies(t *testing.T) {
+	assertT := assert.New(t)
+
+	res, err := MannWhitneyUTest([]float64{1, 2, 2, 3}, []float64{2, 3, 4, 5}, LocationLess)
+	assertT.NoError(err)
+	assertT.False(res.Exact)
+	assertT.Equal(2.5, res.U)
+	assertT.InDelta(0.06832912386907376, res.P, 1e-12)
+
+	_, err = MannWhitneyUTest([]float64{1, 1}, []float64{1}, LocationLess)
+	assertT.ErrorIs(err, ErrSamplesEqual)
+	_, err = MannWhitneyUTest([]float64{1, 2}, nil, LocationDiffers)
+	assertT.ErrorIs(err, ErrSampleSize)
+}
+
+func TestMannWhitneyUTestLarge(t *testing.T) {
+	assertT := assert.New(t)
+
+	x1 := make([]float64, MannWhitneyExactLimit+1)
+	x2 := make([]float64, MannWhitneyExactLimit+1)
+	for i := range x1 {
+		x1[i] = float64(2 * i)
+		x2[i] = float64(2*i + 1)
+	}
+
+	res, err := MannWhitneyUTest(x1, x2, LocationDiffers)
+	assertT.NoError(err)
+	assertT.False(res.Exact)
+	assertT.Greater(res.P, 0.5)
+}
+
+func TestUDistCdf(t *testing.T) {
+	assertT := assert.New(t)
+
+	counts := []float64{1, 1, 2, 3, 4, 4, 5, 4, 4, 3, 2, 1, 1}
+	cdf := uDistCdf(3, 4)
+	assertT.Equal(len(counts), len(cdf))
+	cum := 0.0
+	for i, c := range counts {
+		cum += c
+		assertT.InDelta(cum/35, cdf[i], 1e-12)
+	}
+	assertT.Equal(1.0, cdf[len(cdf)-1])
+}
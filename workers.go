@@ -0,0 +1,61 @@
+package perform
+
+import (
+	"context"
+	"time"
+)
+
+// Run request passed to workers
+type runJob struct {
+	fixture  *taskFixture
+	intended time.Time // scheduled start of open-loop runs; zero for immediate start
+}
+
+// Long-lived worker - executes the run it was started for and then runs from the dispatch queue until it is closed
+func (run *testRun) worker(ctx context.Context, id int, job runJob) {
+	defer run.workerGroup.Done()
+
+	setupStart := time.Now()
+	ctx = run.setupWorker(ctx, id)
+	defer run.teardownWorker(ctx)
+	if !job.intended.IsZero() {
+		// the run waited for worker setup - exclude it from the open-loop latency
+		job.intended = job.intended.Add(time.Since(setupStart))
+	}
+
+	job.execute(ctx)
+	for job := range run.queue {
+		job.execute(ctx)
+	}
+}
+
+// Executes the run in the worker context
+func (job runJob) execute(ctx context.Context) {
+	if job.intended.IsZero() {
+		runOneTask(ctx, job.fixture)
+	} else {
+		runScheduledTask(ctx, job.fixture, job.intended)
+	}
+}
+
+// Hands the run over to an idle worker. A new worker is started with the run when all workers are busy,
+// so that the pool grows up to the throttle capacity only when the load requires it.
+// Should be called by dispatcher after acquiring a throttle slot.
+func (run *testRun) submit(ctx context.Context, job runJob) {
+	run.waitGroup.Add(1)
+	run.trackConcurrency()
+
+	select {
+	case run.queue <- job:
+		return
+	default:
+	}
+
+	if run.workers < cap(run.sema) {
+		run.workerGroup.Add(1)
+		go run.worker(ctx, run.workers, job)
+		run.workers++
+		return
+	}
+	run.queue <- job
+}
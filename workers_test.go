@@ -0,0 +1,53 @@
+package perform
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ManyRuns = 10000
+
+func TestWorkerPool(t *testing.T) {
+	assertT := assert.New(t)
+
+	run := newTestRun([]ContextTask{func(context.Context) error { return nil }}, Parallel, runConfig{})
+	defer run.finish(context.Background())
+
+	assertT.Equal(ManyRuns, run.dispatch(context.Background(), nil, ManyRuns))
+	assertT.LessOrEqual(run.workers, Parallel)
+	assertT.Greater(run.workers, 0)
+	assertT.Equal(ManyRuns, len(run.fixtures[0].runtimes))
+
+	// workers survive between phases
+	workers := run.workers
+	assertT.Equal(ManyRuns, run.dispatch(context.Background(), nil, ManyRuns))
+	assertT.LessOrEqual(run.workers, Parallel)
+	assertT.GreaterOrEqual(run.workers, workers)
+}
+
+func TestWorkerPoolGoroutines(t *testing.T) {
+	assertT := assert.New(t)
+
+	baseline := runtime.NumGoroutine()
+	var maxGoroutines atomic.Int32
+	task := func() error {
+		n := int32(runtime.NumGoroutine())
+		for old := maxGoroutines.Load(); n > old && !maxGoroutines.CompareAndSwap(old, n); old = maxGoroutines.Load() {
+		}
+		return nil
+	}
+
+	stats := RunTest([]TestTask{task}, ManyRuns, Parallel)
+
+	assertT.Equal(ManyRuns, stats[0].Count)
+	assertT.LessOrEqual(int(maxGoroutines.Load()), baseline+Parallel+1)
+	for i := 0; i < 100 && runtime.NumGoroutine() > baseline; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assertT.LessOrEqual(runtime.NumGoroutine(), baseline)
+}